@@ -0,0 +1,149 @@
+package pgn
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// Names of the embedded commands for graphical annotations
+const (
+	CommandColoredSquares = "csl"
+	CommandColoredArrows  = "cal"
+)
+
+const (
+	ERR_SQUARE           = "Expected a square such as e4"
+	ERR_ANNOTATION_COLOR = "Expected annotation color G, R, Y or B"
+)
+
+// AnnotationColor is the color of a highlighted square or an arrow
+type AnnotationColor string
+
+const (
+	AnnotationGreen  AnnotationColor = "G"
+	AnnotationRed    AnnotationColor = "R"
+	AnnotationYellow AnnotationColor = "Y"
+	AnnotationBlue   AnnotationColor = "B"
+)
+
+// Square is a square on the board, such as e4
+type Square struct {
+	File File
+	Rank Rank
+}
+
+// ParseSquare parses a square in algebraic notation, such as "e4"
+func ParseSquare(s string) (Square, error) {
+	if len(s) != 2 || !isFile(rune(s[0])) || !isRank(rune(s[1])) {
+		return Square{}, errors.New(ERR_SQUARE)
+	}
+	return Square{File: File(s[0:1]), Rank: Rank(s[1] - '0')}, nil
+}
+
+func (s Square) String() string {
+	return string(s.File) + strconv.Itoa(int(s.Rank))
+}
+
+// SquareAnnotation is a highlighted square from a [%csl] command, such as Gd4
+type SquareAnnotation struct {
+	Color  AnnotationColor
+	Square Square
+}
+
+func (a SquareAnnotation) String() string {
+	return string(a.Color) + a.Square.String()
+}
+
+// ArrowAnnotation is an arrow from a [%cal] command, such as Ge2e4
+type ArrowAnnotation struct {
+	Color    AnnotationColor
+	From, To Square
+}
+
+func (a ArrowAnnotation) String() string {
+	return string(a.Color) + a.From.String() + a.To.String()
+}
+
+func parseAnnotationColor(s string) (AnnotationColor, error) {
+	c := AnnotationColor(s)
+	switch c {
+	case AnnotationGreen, AnnotationRed, AnnotationYellow, AnnotationBlue:
+		return c, nil
+	}
+	return "", errors.New(ERR_ANNOTATION_COLOR)
+}
+
+// ParseSquareAnnotations parses the value of a [%csl] command, such as
+// "Gd4,Re5"
+func ParseSquareAnnotations(s string) ([]SquareAnnotation, error) {
+	annotations := []SquareAnnotation{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) != 3 {
+			return nil, errors.New(ERR_SQUARE)
+		}
+		color, err := parseAnnotationColor(item[:1])
+		if err != nil {
+			return nil, err
+		}
+		square, err := ParseSquare(item[1:])
+		if err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, SquareAnnotation{Color: color, Square: square})
+	}
+	return annotations, nil
+}
+
+// ParseArrowAnnotations parses the value of a [%cal] command, such as
+// "Ge2e4,Rd8d1"
+func ParseArrowAnnotations(s string) ([]ArrowAnnotation, error) {
+	annotations := []ArrowAnnotation{}
+	for _, item := range strings.Split(s, ",") {
+		item = strings.TrimSpace(item)
+		if len(item) != 5 {
+			return nil, errors.New(ERR_SQUARE)
+		}
+		color, err := parseAnnotationColor(item[:1])
+		if err != nil {
+			return nil, err
+		}
+		from, err := ParseSquare(item[1:3])
+		if err != nil {
+			return nil, err
+		}
+		to, err := ParseSquare(item[3:5])
+		if err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, ArrowAnnotation{Color: color, From: from, To: to})
+	}
+	return annotations, nil
+}
+
+// AddSquare highlights a square after the move
+func (m *Move) AddSquare(color AnnotationColor, square Square) {
+	m.Squares = append(m.Squares, SquareAnnotation{Color: color, Square: square})
+}
+
+// AddArrow draws an arrow after the move
+func (m *Move) AddArrow(color AnnotationColor, from, to Square) {
+	m.Arrows = append(m.Arrows, ArrowAnnotation{Color: color, From: from, To: to})
+}
+
+func formatSquareAnnotations(annotations []SquareAnnotation) string {
+	items := make([]string, len(annotations))
+	for i, a := range annotations {
+		items[i] = a.String()
+	}
+	return strings.Join(items, ",")
+}
+
+func formatArrowAnnotations(annotations []ArrowAnnotation) string {
+	items := make([]string, len(annotations))
+	for i, a := range annotations {
+		items[i] = a.String()
+	}
+	return strings.Join(items, ",")
+}
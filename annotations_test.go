@@ -0,0 +1,80 @@
+package pgn_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestParseSquareAnnotations(t *testing.T) {
+	got, err := pgn.ParseSquareAnnotations("Gd4,Re5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []pgn.SquareAnnotation{
+		pgn.SquareAnnotation{Color: pgn.AnnotationGreen, Square: pgn.Square{File: pgn.FileD, Rank: pgn.Rank4}},
+		pgn.SquareAnnotation{Color: pgn.AnnotationRed, Square: pgn.Square{File: pgn.FileE, Rank: pgn.Rank5}},
+	}
+	if !reflect.DeepEqual(got, exp) {
+		fmt.Println("Got:", got)
+		fmt.Println("Exp:", exp)
+		t.Fatal("Unexpected square annotations")
+	}
+
+	for _, in := range []string{"", "Gd9", "Xd4", "Gd4,"} {
+		if _, err := pgn.ParseSquareAnnotations(in); err == nil {
+			fmt.Println("In:", in)
+			t.Fatal("Expected error")
+		}
+	}
+}
+
+func TestParseArrowAnnotations(t *testing.T) {
+	got, err := pgn.ParseArrowAnnotations("Ge2e4,Bd8h4")
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []pgn.ArrowAnnotation{
+		pgn.ArrowAnnotation{Color: pgn.AnnotationGreen, From: pgn.Square{File: pgn.FileE, Rank: pgn.Rank2}, To: pgn.Square{File: pgn.FileE, Rank: pgn.Rank4}},
+		pgn.ArrowAnnotation{Color: pgn.AnnotationBlue, From: pgn.Square{File: pgn.FileD, Rank: pgn.Rank8}, To: pgn.Square{File: pgn.FileH, Rank: pgn.Rank4}},
+	}
+	if !reflect.DeepEqual(got, exp) {
+		fmt.Println("Got:", got)
+		fmt.Println("Exp:", exp)
+		t.Fatal("Unexpected arrow annotations")
+	}
+
+	for _, in := range []string{"Ge2", "Ge2e9", "Ye2e4,Gz1a1"} {
+		if _, err := pgn.ParseArrowAnnotations(in); err == nil {
+			fmt.Println("In:", in)
+			t.Fatal("Expected error")
+		}
+	}
+}
+
+func TestMoveAnnotations(t *testing.T) {
+	var unmarshalled pgn.PGN
+	err := pgn.Unmarshal("1. e4 {Center! [%csl Gd4,Re5][%cal Ge2e4]} e5", &unmarshalled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	move := &unmarshalled.Games[0].Movetext[0].White
+	if len(move.Squares) != 2 || len(move.Arrows) != 1 || move.Comment != "Center!" {
+		fmt.Println("Got:", move.Squares, move.Arrows, move.Comment)
+		t.Fatal("Unexpected annotations")
+	}
+
+	move.AddArrow(pgn.AnnotationYellow, pgn.Square{File: pgn.FileG, Rank: pgn.Rank1}, pgn.Square{File: pgn.FileF, Rank: pgn.Rank3})
+	black := &unmarshalled.Games[0].Movetext[0].Black
+	black.AddSquare(pgn.AnnotationBlue, pgn.Square{File: pgn.FileF, Rank: pgn.Rank7})
+
+	got := pgn.Marshal(unmarshalled)
+	exp := "1. e4 {[%csl Gd4,Re5] [%cal Ge2e4,Yg1f3] Center!} 1... e5 {[%csl Bf7]} *\n"
+	if got != exp {
+		fmt.Println("Got:", got)
+		fmt.Println("Exp:", exp)
+		t.Fatal("Unexpected PGN")
+	}
+}
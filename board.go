@@ -0,0 +1,716 @@
+package pgn
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const (
+	ERR_FEN            = "Expected FEN with six fields: placement, side to move, castling, en passant, halfmove clock, fullmove number"
+	ERR_FEN_PLACEMENT  = "Expected FEN piece placement of eight ranks of eight squares"
+	ERR_ILLEGAL_MOVE   = "Illegal move"
+	ERR_AMBIGUOUS_MOVE = "Ambiguous move"
+)
+
+// StartingFEN is the standard starting position in Forsyth-Edwards Notation
+const StartingFEN = "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+
+// Color is the color of a player or a piece
+type Color int
+
+const (
+	White Color = iota
+	Black
+)
+
+func (c Color) String() string {
+	if c == White {
+		return "white"
+	}
+	return "black"
+}
+
+// Opponent returns the other color
+func (c Color) Opponent() Color {
+	return 1 - c
+}
+
+// castling rights, as bits
+const (
+	castleWhiteKingside = 1 << iota
+	castleWhiteQueenside
+	castleBlackKingside
+	castleBlackQueenside
+)
+
+// Position is a chess position: the placement of the pieces, the side to
+// move, castling rights, the en passant square and the move clocks.
+//
+// Squares are indexed internally from a1 = 0 to h8 = 63, file first. Each
+// square holds the FEN letter of its piece, upper case for White and lower
+// case for Black, or zero when it is empty.
+type Position struct {
+	board    [64]byte
+	castling int
+	// enPassant is the square a pawn passed over on the last move, or -1
+	enPassant int
+	zobrist   *ZobristTable
+	hash      uint64
+
+	Turn           Color
+	HalfmoveClock  int
+	FullmoveNumber int
+}
+
+// boardMove is a move from one square to another. Castling is a king move of
+// two squares and promotion holds the upper case letter of the new piece.
+type boardMove struct {
+	from, to  int
+	promotion byte
+}
+
+// NewPosition returns the standard starting position
+func NewPosition() Position {
+	p, _ := ParseFEN(StartingFEN)
+	return p
+}
+
+// ParseFEN parses a position in Forsyth-Edwards Notation. The move clocks may
+// be left off, as they are in EPD.
+func ParseFEN(s string) (Position, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 4 {
+		fields = append(fields, "0", "1")
+	}
+	if len(fields) != 6 {
+		return Position{}, errors.New(ERR_FEN)
+	}
+
+	p := Position{enPassant: -1}
+
+	ranks := strings.Split(fields[0], "/")
+	if len(ranks) != 8 {
+		return Position{}, errors.New(ERR_FEN_PLACEMENT)
+	}
+	for i, rank := range ranks {
+		r := 7 - i
+		f := 0
+		for _, c := range rank {
+			switch {
+			case c >= '1' && c <= '8':
+				f += int(c - '0')
+			case strings.ContainsRune("PNBRQKpnbrqk", c):
+				if f > 7 {
+					return Position{}, errors.New(ERR_FEN_PLACEMENT)
+				}
+				p.board[r*8+f] = byte(c)
+				f++
+			default:
+				return Position{}, errors.New(ERR_FEN_PLACEMENT)
+			}
+		}
+		if f != 8 {
+			return Position{}, errors.New(ERR_FEN_PLACEMENT)
+		}
+	}
+
+	switch fields[1] {
+	case "w":
+		p.Turn = White
+	case "b":
+		p.Turn = Black
+	default:
+		return Position{}, errors.New(ERR_FEN)
+	}
+
+	if fields[2] != "-" {
+		for _, c := range fields[2] {
+			switch c {
+			case 'K':
+				p.castling |= castleWhiteKingside
+			case 'Q':
+				p.castling |= castleWhiteQueenside
+			case 'k':
+				p.castling |= castleBlackKingside
+			case 'q':
+				p.castling |= castleBlackQueenside
+			default:
+				return Position{}, errors.New(ERR_FEN)
+			}
+		}
+	}
+
+	if fields[3] != "-" {
+		sq, err := ParseSquare(fields[3])
+		if err != nil {
+			return Position{}, errors.New(ERR_FEN)
+		}
+		p.enPassant = squareIndex(sq)
+	}
+
+	var err error
+	if p.HalfmoveClock, err = strconv.Atoi(fields[4]); err != nil || p.HalfmoveClock < 0 {
+		return Position{}, errors.New(ERR_FEN)
+	}
+	if p.FullmoveNumber, err = strconv.Atoi(fields[5]); err != nil || p.FullmoveNumber < 1 {
+		return Position{}, errors.New(ERR_FEN)
+	}
+
+	p.SetZobristTable(DefaultZobristTable)
+	return p, nil
+}
+
+// FEN returns the position in Forsyth-Edwards Notation
+func (p *Position) FEN() string {
+	var b strings.Builder
+	for r := 7; r >= 0; r-- {
+		empty := 0
+		for f := 0; f < 8; f++ {
+			c := p.board[r*8+f]
+			if c == 0 {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				b.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+			b.WriteByte(c)
+		}
+		if empty > 0 {
+			b.WriteString(strconv.Itoa(empty))
+		}
+		if r > 0 {
+			b.WriteByte('/')
+		}
+	}
+
+	if p.Turn == White {
+		b.WriteString(" w ")
+	} else {
+		b.WriteString(" b ")
+	}
+
+	castling := ""
+	for _, c := range []struct {
+		right  int
+		letter string
+	}{
+		{castleWhiteKingside, "K"},
+		{castleWhiteQueenside, "Q"},
+		{castleBlackKingside, "k"},
+		{castleBlackQueenside, "q"},
+	} {
+		if p.castling&c.right != 0 {
+			castling += c.letter
+		}
+	}
+	if castling == "" {
+		castling = "-"
+	}
+	b.WriteString(castling)
+
+	if p.enPassant >= 0 {
+		b.WriteString(" " + indexSquare(p.enPassant).String())
+	} else {
+		b.WriteString(" -")
+	}
+
+	b.WriteString(" " + strconv.Itoa(p.HalfmoveClock) + " " + strconv.Itoa(p.FullmoveNumber))
+	return b.String()
+}
+
+// PieceAt returns the piece on a square and its color, and reports false if
+// the square is empty
+func (p *Position) PieceAt(s Square) (Piece, Color, bool) {
+	c := p.board[squareIndex(s)]
+	if c == 0 {
+		return "", White, false
+	}
+	return Piece(upper(c)), pieceColor(c), true
+}
+
+// InCheck reports whether the side to move is in check
+func (p *Position) InCheck() bool {
+	return p.inCheck(p.Turn)
+}
+
+// IsCheckmate reports whether the side to move is in check and has no legal
+// move
+func (p *Position) IsCheckmate() bool {
+	return p.inCheck(p.Turn) && len(p.legalMoves()) == 0
+}
+
+// IsStalemate reports whether the side to move is not in check and has no
+// legal move
+func (p *Position) IsStalemate() bool {
+	return !p.inCheck(p.Turn) && len(p.legalMoves()) == 0
+}
+
+// Play resolves a move in SAN against the position and plays it. It returns
+// an error if the move is illegal or does not say which of several pieces
+// moves. The capture, check and checkmate markers are not verified.
+func (p *Position) Play(m Move) error {
+	bm, err := p.resolve(m)
+	if err != nil {
+		return err
+	}
+	p.apply(bm)
+	return nil
+}
+
+// LegalMoves returns every legal move for the side to move, in SAN with the
+// least disambiguation needed and with check and checkmate marked
+func (p *Position) LegalMoves() []Move {
+	legal := p.legalMoves()
+	moves := make([]Move, len(legal))
+	for i, bm := range legal {
+		moves[i] = p.san(bm, legal)
+	}
+	return moves
+}
+
+// san describes a legal move in SAN. legal holds every legal move in the
+// position, to work out the disambiguation.
+func (p *Position) san(bm boardMove, legal []boardMove) Move {
+	m := Move{}
+	piece := upper(p.board[bm.from])
+
+	if piece == 'K' && abs(bm.to-bm.from) == 2 {
+		m.Castle = CastleKingside
+		if bm.to < bm.from {
+			m.Castle = CastleQueenside
+		}
+	} else {
+		to := indexSquare(bm.to)
+		m.File, m.Rank = to.File, to.Rank
+		m.Capture = p.board[bm.to] != 0 || (piece == 'P' && bm.to == p.enPassant)
+		if bm.promotion != 0 {
+			m.Promotion = Piece(bm.promotion)
+		}
+
+		if piece == 'P' {
+			if m.Capture {
+				m.FromFile = indexSquare(bm.from).File
+			}
+		} else {
+			m.Piece = Piece(piece)
+			ambiguous, sameFile, sameRank := false, false, false
+			for _, other := range legal {
+				if other.to != bm.to || other.from == bm.from || upper(p.board[other.from]) != piece {
+					continue
+				}
+				ambiguous = true
+				if fileOf(other.from) == fileOf(bm.from) {
+					sameFile = true
+				}
+				if rankOf(other.from) == rankOf(bm.from) {
+					sameRank = true
+				}
+			}
+			if ambiguous {
+				from := indexSquare(bm.from)
+				if !sameFile {
+					m.FromFile = from.File
+				} else if !sameRank {
+					m.FromRank = from.Rank
+				} else {
+					m.FromFile, m.FromRank = from.File, from.Rank
+				}
+			}
+		}
+	}
+
+	next := *p
+	next.apply(bm)
+	if next.IsCheckmate() {
+		m.Checkmate = true
+	} else if next.InCheck() {
+		m.Check = true
+	}
+	return m
+}
+
+// resolve finds the legal move that a move in SAN describes
+func (p *Position) resolve(m Move) (boardMove, error) {
+	matches := []boardMove{}
+	for _, bm := range p.legalMoves() {
+		if p.matches(bm, m) {
+			matches = append(matches, bm)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return boardMove{}, errors.New(ERR_ILLEGAL_MOVE)
+	case 1:
+		return matches[0], nil
+	}
+	return boardMove{}, errors.New(ERR_AMBIGUOUS_MOVE)
+}
+
+func (p *Position) matches(bm boardMove, m Move) bool {
+	piece := upper(p.board[bm.from])
+
+	if m.Castle != "" {
+		if piece != 'K' || abs(bm.to-bm.from) != 2 {
+			return false
+		}
+		return (m.Castle == CastleKingside) == (bm.to > bm.from)
+	}
+
+	want := byte('P')
+	if m.Piece != "" {
+		want = m.Piece[0]
+	}
+	if piece != want {
+		return false
+	}
+	if piece == 'K' && abs(bm.to-bm.from) == 2 {
+		// castling is written O-O or O-O-O, not as a king move
+		return false
+	}
+
+	if m.File == "" || m.Rank == 0 || bm.to != squareIndex(Square{File: m.File, Rank: m.Rank}) {
+		return false
+	}
+	if m.FromFile != "" && fileOf(bm.from) != int(m.FromFile[0]-'a') {
+		return false
+	}
+	if m.FromRank != 0 && rankOf(bm.from) != int(m.FromRank)-1 {
+		return false
+	}
+
+	promotion := byte(0)
+	if m.Promotion != "" {
+		promotion = m.Promotion[0]
+	}
+	return bm.promotion == promotion
+}
+
+// legalMoves returns every legal move for the side to move
+func (p *Position) legalMoves() []boardMove {
+	legal := []boardMove{}
+	for _, bm := range p.pseudoLegalMoves() {
+		next := *p
+		next.apply(bm)
+		if !next.inCheck(p.Turn) {
+			legal = append(legal, bm)
+		}
+	}
+	return legal
+}
+
+var (
+	knightSteps = [][2]int{{1, 2}, {2, 1}, {2, -1}, {1, -2}, {-1, -2}, {-2, -1}, {-2, 1}, {-1, 2}}
+	kingSteps   = [][2]int{{1, 0}, {1, 1}, {0, 1}, {-1, 1}, {-1, 0}, {-1, -1}, {0, -1}, {1, -1}}
+	rookDirs    = [][2]int{{1, 0}, {0, 1}, {-1, 0}, {0, -1}}
+	bishopDirs  = [][2]int{{1, 1}, {-1, 1}, {-1, -1}, {1, -1}}
+)
+
+// pseudoLegalMoves returns the moves for the side to move without regard to
+// whether they leave the king in check
+func (p *Position) pseudoLegalMoves() []boardMove {
+	moves := []boardMove{}
+	for from := 0; from < 64; from++ {
+		c := p.board[from]
+		if c == 0 || pieceColor(c) != p.Turn {
+			continue
+		}
+		switch upper(c) {
+		case 'P':
+			moves = p.pawnMoves(from, moves)
+		case 'N':
+			moves = p.stepMoves(from, knightSteps, moves)
+		case 'B':
+			moves = p.slideMoves(from, bishopDirs, moves)
+		case 'R':
+			moves = p.slideMoves(from, rookDirs, moves)
+		case 'Q':
+			moves = p.slideMoves(from, bishopDirs, moves)
+			moves = p.slideMoves(from, rookDirs, moves)
+		case 'K':
+			moves = p.stepMoves(from, kingSteps, moves)
+			moves = p.castleMoves(from, moves)
+		}
+	}
+	return moves
+}
+
+func (p *Position) stepMoves(from int, steps [][2]int, moves []boardMove) []boardMove {
+	for _, s := range steps {
+		to, ok := offset(from, s[0], s[1])
+		if !ok {
+			continue
+		}
+		if c := p.board[to]; c == 0 || pieceColor(c) != p.Turn {
+			moves = append(moves, boardMove{from: from, to: to})
+		}
+	}
+	return moves
+}
+
+func (p *Position) slideMoves(from int, dirs [][2]int, moves []boardMove) []boardMove {
+	for _, d := range dirs {
+		to := from
+		for {
+			var ok bool
+			to, ok = offset(to, d[0], d[1])
+			if !ok {
+				break
+			}
+			c := p.board[to]
+			if c != 0 && pieceColor(c) == p.Turn {
+				break
+			}
+			moves = append(moves, boardMove{from: from, to: to})
+			if c != 0 {
+				break
+			}
+		}
+	}
+	return moves
+}
+
+func (p *Position) pawnMoves(from int, moves []boardMove) []boardMove {
+	forward, startRank, lastRank := 1, 1, 7
+	if p.Turn == Black {
+		forward, startRank, lastRank = -1, 6, 0
+	}
+
+	add := func(to int) {
+		if rankOf(to) == lastRank {
+			for _, promotion := range []byte("QRBN") {
+				moves = append(moves, boardMove{from: from, to: to, promotion: promotion})
+			}
+			return
+		}
+		moves = append(moves, boardMove{from: from, to: to})
+	}
+
+	if to, ok := offset(from, 0, forward); ok && p.board[to] == 0 {
+		add(to)
+		if rankOf(from) == startRank {
+			if to2, ok := offset(to, 0, forward); ok && p.board[to2] == 0 {
+				add(to2)
+			}
+		}
+	}
+	for _, df := range []int{-1, 1} {
+		to, ok := offset(from, df, forward)
+		if !ok {
+			continue
+		}
+		if c := p.board[to]; (c != 0 && pieceColor(c) != p.Turn) || to == p.enPassant {
+			add(to)
+		}
+	}
+	return moves
+}
+
+func (p *Position) castleMoves(from int, moves []boardMove) []boardMove {
+	home, kingside, queenside := 4, castleWhiteKingside, castleWhiteQueenside
+	if p.Turn == Black {
+		home, kingside, queenside = 60, castleBlackKingside, castleBlackQueenside
+	}
+	if from != home || p.inCheck(p.Turn) {
+		return moves
+	}
+	opponent := p.Turn.Opponent()
+	rook := byte('R')
+	if p.Turn == Black {
+		rook = 'r'
+	}
+
+	if p.castling&kingside != 0 && p.board[home+3] == rook &&
+		p.board[home+1] == 0 && p.board[home+2] == 0 &&
+		!p.attacked(home+1, opponent) && !p.attacked(home+2, opponent) {
+		moves = append(moves, boardMove{from: home, to: home + 2})
+	}
+	if p.castling&queenside != 0 && p.board[home-4] == rook &&
+		p.board[home-1] == 0 && p.board[home-2] == 0 && p.board[home-3] == 0 &&
+		!p.attacked(home-1, opponent) && !p.attacked(home-2, opponent) {
+		moves = append(moves, boardMove{from: home, to: home - 2})
+	}
+	return moves
+}
+
+// attacked reports whether a piece of the given color attacks the square
+func (p *Position) attacked(sq int, by Color) bool {
+	is := func(to int, letter byte) bool {
+		c := p.board[to]
+		return c != 0 && pieceColor(c) == by && upper(c) == letter
+	}
+
+	pawnRank := -1
+	if by == Black {
+		pawnRank = 1
+	}
+	for _, df := range []int{-1, 1} {
+		if from, ok := offset(sq, df, pawnRank); ok && is(from, 'P') {
+			return true
+		}
+	}
+	for _, s := range knightSteps {
+		if from, ok := offset(sq, s[0], s[1]); ok && is(from, 'N') {
+			return true
+		}
+	}
+	for _, s := range kingSteps {
+		if from, ok := offset(sq, s[0], s[1]); ok && is(from, 'K') {
+			return true
+		}
+	}
+	for _, slider := range []struct {
+		dirs    [][2]int
+		letters string
+	}{
+		{rookDirs, "RQ"},
+		{bishopDirs, "BQ"},
+	} {
+		for _, d := range slider.dirs {
+			from := sq
+			for {
+				var ok bool
+				from, ok = offset(from, d[0], d[1])
+				if !ok {
+					break
+				}
+				c := p.board[from]
+				if c == 0 {
+					continue
+				}
+				if pieceColor(c) == by && strings.IndexByte(slider.letters, upper(c)) >= 0 {
+					return true
+				}
+				break
+			}
+		}
+	}
+	return false
+}
+
+func (p *Position) inCheck(c Color) bool {
+	king := byte('K')
+	if c == Black {
+		king = 'k'
+	}
+	for sq := 0; sq < 64; sq++ {
+		if p.board[sq] == king {
+			return p.attacked(sq, c.Opponent())
+		}
+	}
+	return false
+}
+
+// apply plays a move without checking that it is legal
+func (p *Position) apply(bm boardMove) {
+	c := p.board[bm.from]
+	piece := upper(c)
+	captured := p.board[bm.to] != 0
+	p.toggleState()
+
+	if piece == 'P' && bm.to == p.enPassant {
+		// the captured pawn is beside the moving pawn, not on the target
+		victim := bm.to - 8
+		if p.Turn == Black {
+			victim = bm.to + 8
+		}
+		p.put(victim, 0)
+		captured = true
+	}
+
+	if piece == 'K' && abs(bm.to-bm.from) == 2 {
+		if bm.to > bm.from {
+			p.put(bm.from+1, p.board[bm.from+3])
+			p.put(bm.from+3, 0)
+		} else {
+			p.put(bm.from-1, p.board[bm.from-4])
+			p.put(bm.from-4, 0)
+		}
+	}
+
+	if bm.promotion != 0 {
+		c = bm.promotion
+		if p.Turn == Black {
+			c = bm.promotion + 'a' - 'A'
+		}
+	}
+	p.put(bm.to, c)
+	p.put(bm.from, 0)
+
+	p.enPassant = -1
+	if piece == 'P' && abs(bm.to-bm.from) == 16 {
+		p.enPassant = (bm.from + bm.to) / 2
+	}
+
+	for _, sq := range []int{bm.from, bm.to} {
+		switch sq {
+		case 4:
+			p.castling &^= castleWhiteKingside | castleWhiteQueenside
+		case 7:
+			p.castling &^= castleWhiteKingside
+		case 0:
+			p.castling &^= castleWhiteQueenside
+		case 60:
+			p.castling &^= castleBlackKingside | castleBlackQueenside
+		case 63:
+			p.castling &^= castleBlackKingside
+		case 56:
+			p.castling &^= castleBlackQueenside
+		}
+	}
+
+	if piece == 'P' || captured {
+		p.HalfmoveClock = 0
+	} else {
+		p.HalfmoveClock++
+	}
+	if p.Turn == Black {
+		p.FullmoveNumber++
+	}
+	p.Turn = p.Turn.Opponent()
+	p.toggleState()
+}
+
+func squareIndex(s Square) int {
+	return int(s.File[0]-'a') + 8*(int(s.Rank)-1)
+}
+
+func indexSquare(i int) Square {
+	return Square{File: File(rune('a' + fileOf(i))), Rank: Rank(rankOf(i) + 1)}
+}
+
+func fileOf(i int) int { return i % 8 }
+func rankOf(i int) int { return i / 8 }
+
+// offset moves a square by whole files and ranks, and reports false if that
+// leaves the board
+func offset(sq, files, ranks int) (int, bool) {
+	f, r := fileOf(sq)+files, rankOf(sq)+ranks
+	if f < 0 || f > 7 || r < 0 || r > 7 {
+		return 0, false
+	}
+	return r*8 + f, true
+}
+
+func upper(c byte) byte {
+	if c >= 'a' && c <= 'z' {
+		return c - 'a' + 'A'
+	}
+	return c
+}
+
+func pieceColor(c byte) Color {
+	if c >= 'a' && c <= 'z' {
+		return Black
+	}
+	return White
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
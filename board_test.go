@@ -0,0 +1,143 @@
+package pgn_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/miketmoore/pgn"
+)
+
+// perft counts the leaf nodes of the move tree to the given depth
+func perft(p pgn.Position, depth int) int {
+	if depth == 0 {
+		return 1
+	}
+	moves := p.LegalMoves()
+	if depth == 1 {
+		return len(moves)
+	}
+	n := 0
+	for _, m := range moves {
+		next := p
+		if err := next.Play(m); err != nil {
+			panic(err)
+		}
+		n += perft(next, depth-1)
+	}
+	return n
+}
+
+func TestPerft(t *testing.T) {
+	data := []struct {
+		name  string
+		fen   string
+		depth int
+		nodes int
+	}{
+		{name: "Start", fen: pgn.StartingFEN, depth: 3, nodes: 8902},
+		{name: "Kiwipete", fen: "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", depth: 2, nodes: 2039},
+		{name: "En passant and pins", fen: "8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1", depth: 3, nodes: 2812},
+		{name: "Promotions", fen: "r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1", depth: 2, nodes: 264},
+	}
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := pgn.ParseFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := perft(p, test.depth)
+			if got != test.nodes {
+				fmt.Println("Got:", got)
+				fmt.Println("Exp:", test.nodes)
+				t.Fatal("Unexpected node count")
+			}
+		})
+	}
+}
+
+func TestParseFEN(t *testing.T) {
+	for _, fen := range []string{
+		pgn.StartingFEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6 0 2",
+	} {
+		p, err := pgn.ParseFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if p.FEN() != fen {
+			fmt.Println("Got:", p.FEN())
+			fmt.Println("Exp:", fen)
+			t.Fatal("FEN did not format back to its input")
+		}
+	}
+
+	for _, fen := range []string{
+		"",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP w KQkq - 0 1",
+		"rnbqkbnr/pppppppp/9/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR x KQkq - 0 1",
+	} {
+		if _, err := pgn.ParseFEN(fen); err == nil {
+			fmt.Println("In:", fen)
+			t.Fatal("Expected error")
+		}
+	}
+}
+
+func TestPositionPlay(t *testing.T) {
+	var unmarshalled pgn.PGN
+	b := "1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 4. Ba4 Nf6 5. O-O Be7 6. Re1 b5 7. Bb3 d6 8. c3 O-O"
+	if err := pgn.Unmarshal(b, &unmarshalled); err != nil {
+		t.Fatal(err)
+	}
+	p := pgn.NewPosition()
+	for _, mt := range unmarshalled.Games[0].Movetext {
+		for _, m := range []pgn.Move{mt.White, mt.Black} {
+			if err := p.Play(m); err != nil {
+				fmt.Println("Move:", m)
+				t.Fatal(err)
+			}
+		}
+	}
+	exp := "r1bq1rk1/2p1bppp/p1np1n2/1p2p3/4P3/1BP2N2/PP1P1PPP/RNBQR1K1 w - - 1 9"
+	if p.FEN() != exp {
+		fmt.Println("Got:", p.FEN())
+		fmt.Println("Exp:", exp)
+		t.Fatal("Unexpected position")
+	}
+
+	for _, test := range []struct {
+		fen  string
+		move pgn.Move
+		err  string
+	}{
+		{fen: pgn.StartingFEN, move: pgn.Move{File: pgn.FileE, Rank: pgn.Rank5}, err: pgn.ERR_ILLEGAL_MOVE},
+		{fen: "4k3/8/8/8/8/8/8/2N1K1N1 w - - 0 1", move: pgn.Move{Piece: pgn.PieceKnight, File: pgn.FileE, Rank: pgn.Rank2}, err: pgn.ERR_AMBIGUOUS_MOVE},
+		{fen: "4k3/8/8/8/8/8/8/2N1K1N1 w - - 0 1", move: pgn.Move{Piece: pgn.PieceKnight, FromFile: pgn.FileG, File: pgn.FileE, Rank: pgn.Rank2}},
+	} {
+		p, _ := pgn.ParseFEN(test.fen)
+		err := p.Play(test.move)
+		if (err == nil && test.err != "") || (err != nil && err.Error() != test.err) {
+			fmt.Println("Move:", test.move, "Got:", err)
+			t.Fatal("Unexpected result")
+		}
+	}
+}
+
+func TestLegalMovesSAN(t *testing.T) {
+	p, err := pgn.ParseFEN("6k1/5ppp/8/R7/8/8/8/RN3NK1 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	got := map[string]bool{}
+	for _, m := range p.LegalMoves() {
+		got[m.String()] = true
+	}
+	for _, san := range []string{"R1a3", "R5a3", "Nbd2", "Nfd2", "Ra8#", "Rb5"} {
+		if !got[san] {
+			fmt.Println("Got:", got)
+			t.Fatal("Missing move " + san)
+		}
+	}
+}
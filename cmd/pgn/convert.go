@@ -0,0 +1,39 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/miketmoore/pgn"
+)
+
+// convertCommand writes the games of all the files as one JSON array
+func convertCommand(args []string) int {
+	flags := flagSet("convert", "[-format json] [file...]")
+	format := flags.String("format", "json", "output format; only json is supported")
+	flags.Parse(args)
+
+	if *format != "json" {
+		fmt.Fprintf(os.Stderr, "pgn convert: unknown format %q\n", *format)
+		return 2
+	}
+
+	games := []pgn.Game{}
+	code := eachInput(flags.Args(), func(in input) (bool, error) {
+		_, unmarshalled, err := in.parse()
+		if err != nil {
+			return false, err
+		}
+		games = append(games, unmarshalled.Games...)
+		return true, nil
+	})
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(games); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	return code
+}
@@ -0,0 +1,50 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/miketmoore/pgn"
+)
+
+// filterCommand writes the games of the files that match a query to standard
+// output, each copied as it is in its file
+func filterCommand(args []string) int {
+	flags := flagSet("filter", "query [file...]")
+	flags.Parse(args)
+
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+	query, err := pgn.ParseQuery(flags.Arg(0))
+	var syntaxErr *pgn.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		fmt.Fprintf(os.Stderr, "pgn filter: %s\n  %s\n  %s^\n", syntaxErr.Message, flags.Arg(0),
+			strings.Repeat(" ", syntaxErr.Pos.Column-1))
+		return 2
+	}
+
+	return eachInput(flags.Args()[1:], func(in input) (bool, error) {
+		b, err := in.read()
+		if err != nil {
+			return false, err
+		}
+		doc, err := pgn.ParseDocument(pgn.Decode(b, pgn.EncodingAuto))
+		if err != nil {
+			return false, in.error(err)
+		}
+		for _, node := range doc.Games() {
+			game, err := pgn.UnmarshalGame(node)
+			if err != nil {
+				return false, in.error(err)
+			}
+			if query.Match(&game) {
+				fmt.Print(node.Text() + "\n\n")
+			}
+		}
+		return true, nil
+	})
+}
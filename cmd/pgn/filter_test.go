@@ -0,0 +1,22 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	short := "[Event \"Short\"]\n\n1. e4 e5 *"
+	annotated := "[Event \"Annotated\"]\n\n1. e4 $1 e5 2. Nf3 (2. Nc3 {Vienna} Nf6) Nc6! 3. Bb5 a6 *"
+	path := writeFile(t, "games.pgn", []byte(short+"\n\n"+annotated+"\n"))
+
+	got, code := run(t, filterCommand, "plies > 4", path)
+	if code != 0 {
+		t.Fatalf("Got exit code %d", code)
+	}
+	if exp := annotated + "\n\n"; got != exp {
+		fmt.Printf("Got: %q\n", got)
+		fmt.Printf("Exp: %q\n", exp)
+		t.Fatal("Unexpected games")
+	}
+}
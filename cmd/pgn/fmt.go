@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/miketmoore/pgn"
+)
+
+var bomUTF8 = []byte{0xEF, 0xBB, 0xBF}
+
+// fmtCommand rewrites files in the PGN export format. Like gofmt, it prints
+// the formatted games to standard output unless told to list, diff or write
+// the files instead. Files keep their encoding, and a UTF-8 byte order mark,
+// unless -utf8 asks for them to be transcoded. A file whose formatting would
+// lose any of its moves, NAGs, variations, comment text or escape lines is
+// reported as an error and left alone.
+func fmtCommand(args []string) int {
+	flags := flagSet("fmt", "[-l] [-d] [-w] [-utf8] [file...]")
+	list := flags.Bool("l", false, "list files whose formatting differs from pgn fmt's")
+	diff := flags.Bool("d", false, "display diffs instead of rewriting files")
+	write := flags.Bool("w", false, "write result to (source) file instead of stdout")
+	toUTF8 := flags.Bool("utf8", false, "transcode files to UTF-8 without a byte order mark")
+	flags.Parse(args)
+
+	return eachInput(flags.Args(), func(in input) (bool, error) {
+		original, text, err := in.decode()
+		if err != nil {
+			return false, err
+		}
+		var unmarshalled pgn.PGN
+		if err := pgn.Unmarshal(text, &unmarshalled); err != nil {
+			return false, in.error(err)
+		}
+		exported := pgn.MarshalExport(unmarshalled)
+		if err := sameContent(text, exported); err != nil {
+			return false, in.error(err)
+		}
+
+		enc := pgn.DetectEncoding(original)
+		if *toUTF8 {
+			enc = pgn.EncodingUTF8
+		}
+		formatted, err := pgn.Encode(exported, enc)
+		if err != nil {
+			return false, in.error(err)
+		}
+		if !*toUTF8 && bytes.HasPrefix(original, bomUTF8) {
+			formatted = append(append([]byte{}, bomUTF8...), formatted...)
+		}
+
+		if !*list && !*diff && !*write {
+			_, err := os.Stdout.Write(formatted)
+			return true, err
+		}
+		if bytes.Equal(original, formatted) {
+			return true, nil
+		}
+		if *list {
+			fmt.Println(in.name)
+		}
+		if *write && !in.isStdin() {
+			info, err := os.Stat(in.path)
+			if err != nil {
+				return false, err
+			}
+			if err := ioutil.WriteFile(in.path, formatted, info.Mode().Perm()); err != nil {
+				return false, err
+			}
+		}
+		if *write && in.isStdin() {
+			os.Stdout.Write(formatted)
+		}
+		if *diff {
+			d, err := diffBytes(in.name, original, formatted)
+			if err != nil {
+				return false, fmt.Errorf("computing diff: %s", err)
+			}
+			os.Stdout.Write(d)
+		}
+		return true, nil
+	})
+}
+
+// content counts what a PGN text holds, apart from its layout
+type content struct {
+	games, moves, nags, variations, commentWords, escapes int
+}
+
+// countContent counts the content of a syntax tree
+func countContent(t pgn.Token, c *content) {
+	switch t.Type {
+	case pgn.TokenGame:
+		c.games++
+	case pgn.TokenMove:
+		c.moves++
+	case pgn.TokenNAG:
+		c.nags++
+	case pgn.TokenVariation:
+		c.variations++
+	case pgn.TokenEscape:
+		c.escapes++
+	case pgn.TokenComment:
+		text := strings.TrimPrefix(t.Value, ";")
+		text = strings.TrimSuffix(strings.TrimPrefix(text, "{"), "}")
+		c.commentWords += len(strings.Fields(text))
+	}
+	for _, child := range t.Children {
+		countContent(child, c)
+	}
+}
+
+// sameContent checks that the formatted text holds everything the original
+// does, comparing their lossless syntax trees, so that fmt never writes a
+// file that has lost part of its movetext
+func sameContent(original, formatted string) error {
+	var before, after content
+	for i, text := range []string{original, formatted} {
+		doc, err := pgn.ParseDocument(text)
+		if err != nil {
+			return err
+		}
+		counts := &before
+		if i == 1 {
+			counts = &after
+		}
+		countContent(doc.Root, counts)
+	}
+	for _, check := range []struct {
+		name          string
+		before, after int
+	}{
+		{"games", before.games, after.games},
+		{"moves", before.moves, after.moves},
+		{"NAGs", before.nags, after.nags},
+		{"variations", before.variations, after.variations},
+		{"comment text", before.commentWords, after.commentWords},
+		{"escape lines", before.escapes, after.escapes},
+	} {
+		if check.before != check.after {
+			return fmt.Errorf("formatting would lose %s, not writing the file", check.name)
+		}
+	}
+	return nil
+}
+
+// diffBytes runs diff -u on the original and formatted contents, as gofmt -d
+// does
+func diffBytes(name string, original, formatted []byte) ([]byte, error) {
+	dir, err := ioutil.TempDir("", "pgnfmt")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	a, b := dir+"/orig", dir+"/formatted"
+	if err := ioutil.WriteFile(a, original, 0600); err != nil {
+		return nil, err
+	}
+	if err := ioutil.WriteFile(b, formatted, 0600); err != nil {
+		return nil, err
+	}
+
+	out, err := exec.Command("diff", "-u", "--label", name+".orig", "--label", name, a, b).Output()
+	if len(out) > 0 {
+		// diff exits with status 1 when the files differ
+		return out, nil
+	}
+	return out, err
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"testing"
+)
+
+func TestFmtWrite(t *testing.T) {
+	data := []struct {
+		name  string
+		flags []string
+		in    string
+		out   string
+		code  int
+	}{
+		{
+			name: "NAGs and variations",
+			in:   "1.e4!  e5 2. Nf3 (2. Nc3 {Vienna}) Nc6 $14 1-0\n",
+			out:  "1. e4 $1 e5 2. Nf3 (2. Nc3 {Vienna}) 2... Nc6 $14 1-0\n",
+		},
+		{
+			name: "Escape lines",
+			in:   "% exported by an engine\n1. e4 e5 *\n",
+			out:  "% exported by an engine\n1. e4 e5 *\n",
+			code: 2,
+		},
+		{
+			name: "Syntax error",
+			in:   "1. e4 e5 2. Nf3 @ *\n",
+			out:  "1. e4 e5 2. Nf3 @ *\n",
+			code: 2,
+		},
+		{
+			name: "Latin-1",
+			in:   "[White \"Caf\xe9\"]\n\n1.e4 *\n",
+			out:  "[White \"Caf\xe9\"]\n\n1. e4 *\n",
+		},
+		{
+			name: "UTF-8 byte order mark",
+			in:   "\xEF\xBB\xBF[White \"Café\"]\n\n1.e4 *\n",
+			out:  "\xEF\xBB\xBF[White \"Café\"]\n\n1. e4 *\n",
+		},
+		{
+			name:  "Transcoded to UTF-8",
+			flags: []string{"-utf8"},
+			in:    "[White \"Caf\xe9\"]\n\n1.e4 *\n",
+			out:   "[White \"Café\"]\n\n1. e4 *\n",
+		},
+	}
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeFile(t, "game.pgn", []byte(test.in))
+			args := append(append([]string{"-w"}, test.flags...), path)
+			if _, code := run(t, fmtCommand, args...); code != test.code {
+				t.Fatalf("Got exit code %d, expected %d", code, test.code)
+			}
+			got, err := ioutil.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != test.out {
+				fmt.Printf("Got: %q\n", got)
+				fmt.Printf("Exp: %q\n", test.out)
+				t.Fatal("Unexpected file contents")
+			}
+		})
+	}
+}
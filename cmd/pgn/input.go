@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/miketmoore/pgn"
+)
+
+// stdin is the file argument, and the name in diagnostics, for standard input
+const (
+	stdin     = "-"
+	stdinName = "<standard input>"
+)
+
+// input is one file named on the command line
+type input struct {
+	name string
+	path string
+}
+
+func (in input) isStdin() bool {
+	return in.path == stdin
+}
+
+// read returns the contents of the file, or of standard input
+func (in input) read() ([]byte, error) {
+	if in.isStdin() {
+		return ioutil.ReadAll(os.Stdin)
+	}
+	return ioutil.ReadFile(in.path)
+}
+
+// decode reads the file and decodes it to UTF-8, detecting its encoding. It
+// returns the file's bytes along with the text.
+func (in input) decode() ([]byte, string, error) {
+	b, err := in.read()
+	if err != nil {
+		return nil, "", err
+	}
+	return b, pgn.Decode(b, pgn.EncodingAuto), nil
+}
+
+// parse reads the file and unmarshals its games. Syntax errors are returned
+// as file:line:col: message.
+func (in input) parse() ([]byte, pgn.PGN, error) {
+	var unmarshalled pgn.PGN
+	b, text, err := in.decode()
+	if err != nil {
+		return nil, unmarshalled, err
+	}
+	err = pgn.Unmarshal(text, &unmarshalled)
+	return b, unmarshalled, in.error(err)
+}
+
+// error prefixes err with the file name, and with the line and column for a
+// syntax error, in the form lint reports issues
+func (in input) error(err error) error {
+	var syntaxErr *pgn.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return fmt.Errorf("%s:%d:%d: %s: %s", in.name, syntaxErr.Pos.Line, syntaxErr.Pos.Column, pgn.SeverityError, syntaxErr.Message)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %s: %s", in.name, pgn.SeverityError, err)
+	}
+	return nil
+}
+
+// inputs expands the file arguments of a command. Globs are expanded, and a
+// glob that matches nothing is an error, as it is in most shells. With no
+// arguments the command reads standard input.
+func inputs(args []string) ([]input, error) {
+	if len(args) == 0 {
+		return []input{{name: stdinName, path: stdin}}, nil
+	}
+	ins := []input{}
+	for _, arg := range args {
+		if arg == stdin {
+			ins = append(ins, input{name: stdinName, path: stdin})
+			continue
+		}
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s", arg, err)
+		}
+		if matches == nil {
+			if _, err := os.Stat(arg); err != nil {
+				return nil, err
+			}
+			matches = []string{arg}
+		}
+		for _, match := range matches {
+			ins = append(ins, input{name: match, path: match})
+		}
+	}
+	return ins, nil
+}
+
+// flagSet returns the flags of a command with a usage message naming it
+func flagSet(name, args string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	flags.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: pgn %s %s\n", name, args)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// eachInput runs fn on every input named by the arguments, printing its
+// errors. It returns exit code 1 when fn reports problems in a file and 2 when
+// a file could not be read or parsed.
+func eachInput(args []string, fn func(in input) (bool, error)) int {
+	ins, err := inputs(args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	code := 0
+	for _, in := range ins {
+		ok, err := fn(in)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			code = 2
+		} else if !ok && code == 0 {
+			code = 1
+		}
+	}
+	return code
+}
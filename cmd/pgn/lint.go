@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/miketmoore/pgn"
+)
+
+// lintCommand prints the syntax errors of each file and the tag, move and
+// checkmate issues of every game as file:line:col: severity: message. It
+// exits with 1 when any error was found. Warnings are printed but do not fail
+// a file. With -draws, game results are also audited against the draw rules.
+func lintCommand(args []string) int {
+	flags := flagSet("lint", "[-draws] [file...]")
+	draws := flags.Bool("draws", false, "check results against repetition, move-count and material draws")
+	flags.Parse(args)
+
+	return eachInput(flags.Args(), func(in input) (bool, error) {
+		_, unmarshalled, err := in.parse()
+		if err != nil {
+			fmt.Println(err)
+			return false, nil
+		}
+
+		ok := true
+		for i := range unmarshalled.Games {
+			game := &unmarshalled.Games[i]
+			issues := append(pgn.ValidateTags(game), pgn.ValidateMoves(game)...)
+			issues = append(issues, pgn.ValidateMate(game)...)
+			if *draws {
+				issues = append(issues, pgn.ValidateDraws(game)...)
+			}
+			for _, issue := range issues {
+				pos := issue.Pos
+				if pos.Line == 0 {
+					pos = gamePos(game)
+				}
+				fmt.Printf("%s:%d:%d: %s\n", in.name, pos.Line, pos.Column, issue)
+				if issue.Severity == pgn.SeverityError {
+					ok = false
+				}
+			}
+		}
+		return ok, nil
+	})
+}
+
+// gamePos is where a game starts, used for issues that belong to the game as
+// a whole, such as a missing tag.
+func gamePos(game *pgn.Game) pgn.Pos {
+	if len(game.TagPairs) > 0 {
+		return game.TagPairs[0].Pos
+	}
+	for _, movetext := range game.Movetext {
+		return movetext.White.Pos
+	}
+	return pgn.Pos{Line: 1, Column: 1}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+const roster = "[Event \"?\"]\n[Site \"?\"]\n[Date \"????.??.??\"]\n[Round \"?\"]\n" +
+	"[White \"?\"]\n[Black \"?\"]\n[Result \"1-0\"]\n\n"
+
+func TestLint(t *testing.T) {
+	data := []struct {
+		name string
+		in   string
+		code int
+		out  []string
+	}{
+		{
+			name: "NAGs and variations",
+			in:   roster + "1. e4 $1 e5 2. Nf3 (2. Nc3) Nc6!? 3. Bb5 1-0\n",
+			code: 0,
+			out:  []string{},
+		},
+		{
+			name: "Illegal move after a variation",
+			in:   roster + "1. e4 $1 e5 2. Nf3 (2. Nc3) Nc6 3. Bb6 1-0\n",
+			code: 1,
+			out:  []string{"game.pgn:9:36: error: Illegal move: 3. Bb6"},
+		},
+		{
+			name: "Unexpected character",
+			in:   roster + "1. e4 e5 2. Nf3 @ 1-0\n",
+			code: 1,
+			out:  []string{"game.pgn:9:17: error: Unexpected character"},
+		},
+	}
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeFile(t, "game.pgn", []byte(test.in))
+			got, code := run(t, lintCommand, path)
+			if code != test.code {
+				fmt.Println("Got:", got)
+				t.Fatalf("Got exit code %d, expected %d", code, test.code)
+			}
+			if test.out == nil {
+				return
+			}
+			lines := []string{}
+			for _, line := range strings.Split(strings.TrimSpace(got), "\n") {
+				if line != "" {
+					lines = append(lines, strings.TrimPrefix(line, path[:len(path)-len("game.pgn")]))
+				}
+			}
+			if strings.Join(lines, "\n") != strings.Join(test.out, "\n") {
+				fmt.Println("Got:", lines)
+				fmt.Println("Exp:", test.out)
+				t.Fatal("Unexpected lint output")
+			}
+		})
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/miketmoore/pgn"
+)
+
+var identities = map[string]pgn.Identity{
+	pgn.IdentityMovetext.String():      pgn.IdentityMovetext,
+	pgn.IdentityGame.String():          pgn.IdentityGame,
+	pgn.IdentityFinalPosition.String(): pgn.IdentityFinalPosition,
+}
+
+// mergeCommand writes the games of all the files to standard output, leaving
+// out every game that duplicates an earlier one
+func mergeCommand(args []string) int {
+	flags := flagSet("merge", "[-by movetext|game|position] [file...]")
+	by := flags.String("by", "game", "when games are duplicates: same movetext, "+
+		"same players, date, result and moves, or same final position")
+	flags.Parse(args)
+
+	identity, ok := identities[*by]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "pgn merge: unknown identity %q\n", *by)
+		return 2
+	}
+
+	databases := []pgn.PGN{}
+	total := 0
+	code := eachInput(flags.Args(), func(in input) (bool, error) {
+		_, unmarshalled, err := in.parse()
+		if err != nil {
+			return false, err
+		}
+		databases = append(databases, unmarshalled)
+		total += len(unmarshalled.Games)
+		return true, nil
+	})
+
+	merged := pgn.Merge(identity, databases...)
+	fmt.Print(pgn.Marshal(merged))
+	fmt.Fprintf(os.Stderr, "%d games, %d duplicates removed\n", len(merged.Games), total-len(merged.Games))
+	return code
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"fmt"
+)
+
+func parseCommand(args []string) int {
+	flags := flagSet("parse", "[file...]")
+	flags.Parse(args)
+
+	return eachInput(flags.Args(), func(in input) (bool, error) {
+		_, unmarshalled, err := in.parse()
+		if err != nil {
+			return false, err
+		}
+		fmt.Printf("%s: %d games\n", in.name, len(unmarshalled.Games))
+		return true, nil
+	})
+}
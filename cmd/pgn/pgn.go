@@ -1,35 +1,66 @@
+// Command pgn reads, checks and rewrites Portable Game Notation files.
+//
+// Usage:
+//
+//	pgn <command> [flags] [file...]
+//
+// Files may be globs, which are expanded as the shell would, and "-" reads
+// standard input. With no files, commands read standard input.
 package main
 
 import (
-	"flag"
 	"fmt"
-	"io/ioutil"
 	"os"
-
-	"github.com/miketmoore/pgn"
+	"sort"
 )
 
-func main() {
+// command runs with its arguments and returns the exit code
+type command struct {
+	run   func(args []string) int
+	usage string
+}
 
-	file := flag.TokenString("input", "", "Path to a *.pgn file containing zero or more games")
-	flag.Parse()
+var commands = map[string]command{
+	"tokens":  {tokensCommand, "print the tokens of each file, or count them with -count"},
+	"parse":   {parseCommand, "parse each file and count its games"},
+	"lint":    {lintCommand, "report syntax, tag and move errors"},
+	"fmt":     {fmtCommand, "rewrite games in the PGN export format"},
+	"convert": {convertCommand, "convert games to JSON"},
+	"stats":   {statsCommand, "summarize games, results and plies"},
+	"split":   {splitCommand, "split files into one file per game or per chunk"},
+	"merge":   {mergeCommand, "combine files, removing duplicate games"},
+	"filter":  {filterCommand, "print the games that match a query"},
+	"search":  {searchCommand, "find the games and plies where a position occurs"},
+}
 
-	bytes, err := ioutil.ReadFile(*file)
-	if err != nil {
-		fmt.Printf("Error reading file: %s\n", *file)
-		os.Exit(1)
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
 	}
-
-	scanner := pgn.NewScanner(string(bytes))
-	lexer := pgn.NewLexer(scanner)
-
-	tokens := []pgn.Token{}
-	err, tokens = lexer.Tokenize(tokens)
-	if err != nil {
-		os.Exit(1)
+	cmd, ok := commands[os.Args[1]]
+	if !ok {
+		if os.Args[1] != "help" && os.Args[1] != "-h" && os.Args[1] != "-help" {
+			fmt.Fprintf(os.Stderr, "pgn: unknown command %q\n", os.Args[1])
+		}
+		usage()
+		os.Exit(2)
 	}
+	os.Exit(cmd.run(os.Args[2:]))
+}
 
-	fmt.Println("Total tokens parsed: ", len(tokens))
-
-	os.Exit(0)
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: pgn <command> [flags] [file...]")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Files may be globs, and - reads standard input.")
+	fmt.Fprintln(os.Stderr, "")
+	fmt.Fprintln(os.Stderr, "Commands:")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(os.Stderr, "  %-8s %s\n", name, commands[name].usage)
+	}
 }
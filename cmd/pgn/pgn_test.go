@@ -0,0 +1,40 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// run runs a command with its standard output captured, returning the output
+// and the exit code
+func run(t *testing.T, cmd func(args []string) int, args ...string) (string, int) {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	out := make(chan string)
+	go func() {
+		b, _ := ioutil.ReadAll(r)
+		out <- string(b)
+	}()
+	code := cmd(args)
+	w.Close()
+	return <-out, code
+}
+
+// writeFile writes a file in a temporary directory and returns its path
+func writeFile(t *testing.T, name string, contents []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := ioutil.WriteFile(path, contents, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/miketmoore/pgn"
+)
+
+// searchCommand prints every game and run of plies of the files where a
+// position occurs, given as a FEN or as a pattern of squares, or where the
+// material fits a description. All the conditions given must hold.
+func searchCommand(args []string) int {
+	flags := flagSet("search", "[-fen FEN] [-pattern pattern] [-material KQ v KRR] [-rook-ending] "+
+		"[-pawns-one-wing] [-opposite-bishops] [-imbalance] [-min-plies n] [file...]")
+	fen := flags.String("fen", "", "position to find, in Forsyth-Edwards Notation")
+	pattern := flags.String("pattern", "", "board pattern to find: a FEN placement with ? for any square, "+
+		"optionally followed by w or b")
+	material := flags.String("material", "", "material of the two sides, such as KQ v KRR, held by either side, with pawns compared only if given")
+	rookEnding := flags.Bool("rook-ending", false, "find rook endings: a rook each and pawns")
+	oneWing := flags.Bool("pawns-one-wing", false, "find positions with pawns on one wing only")
+	oppositeBishops := flags.Bool("opposite-bishops", false, "find opposite-colored bishops")
+	imbalance := flags.Bool("imbalance", false, "find material imbalances, the sides having different pieces")
+	minPlies := flags.Int("min-plies", 1, "report only runs of at least this many consecutive plies")
+	flags.Parse(args)
+
+	matches := []func(*pgn.Position) bool{}
+	if *fen != "" {
+		p, err := pgn.ParseFEN(*fen)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "pgn search: %s\n", err)
+			return 2
+		}
+		matches = append(matches, pgn.SamePosition(p))
+	}
+	if *pattern != "" {
+		pp, err := pgn.ParsePositionPattern(*pattern)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "pgn search: %s\n", err)
+			return 2
+		}
+		matches = append(matches, pp.Match)
+	}
+	if *material != "" {
+		m, err := pgn.ParseMaterial(*material)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "pgn search: %s\n", err)
+			return 2
+		}
+		matches = append(matches, pgn.MaterialIs(m))
+	}
+	for _, condition := range []struct {
+		set   bool
+		match func(*pgn.Position) bool
+	}{
+		{*rookEnding, pgn.IsRookEnding},
+		{*oneWing, pgn.PawnsOnOneWing},
+		{*oppositeBishops, pgn.OppositeColoredBishops},
+		{*imbalance, pgn.IsImbalanced},
+	} {
+		if condition.set {
+			matches = append(matches, condition.match)
+		}
+	}
+	if len(matches) == 0 {
+		flags.Usage()
+		return 2
+	}
+	match := pgn.All(matches...)
+
+	found := false
+	code := eachInput(flags.Args(), func(in input) (bool, error) {
+		_, unmarshalled, err := in.parse()
+		if err != nil {
+			return false, err
+		}
+		for i := range unmarshalled.Games {
+			game := &unmarshalled.Games[i]
+			for _, run := range game.FindRuns(match, *minPlies) {
+				found = true
+				pos := gamePos(game)
+				fmt.Printf("%s:%d:%d: game %d, %s - %s: %s\n", in.name, pos.Line, pos.Column, i+1,
+					game.White(), game.Black(), describeRun(run))
+			}
+		}
+		return true, nil
+	})
+	if code == 0 && !found {
+		return 1
+	}
+	return code
+}
+
+// describeRun names a run of plies by its first and last ply, such as
+// "plies 4 to 9, 2... Nc6 to 5. Nxe5"
+func describeRun(run pgn.PlyRange) string {
+	if run.Len() == 1 {
+		return describePly(run.First)
+	}
+	return "plies " + strconv.Itoa(run.First.Index) + " to " + strconv.Itoa(run.Last.Index) + ", " +
+		describeMove(run.First) + " to " + describeMove(run.Last)
+}
+
+// describePly names a ply as its index and move, such as "ply 4, 2... Nc6"
+func describePly(ply pgn.Ply) string {
+	if ply.Index == 0 {
+		return "starting position"
+	}
+	return "ply " + strconv.Itoa(ply.Index) + ", " + describeMove(ply)
+}
+
+// describeMove writes the move of a ply with its number, such as "2... Nc6"
+func describeMove(ply pgn.Ply) string {
+	if ply.Index == 0 {
+		return "the start"
+	}
+	return ply.Numbered()
+}
@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/miketmoore/pgn"
+)
+
+// templateField matches a {Name} field in an output file name template
+var templateField = regexp.MustCompile(`\{([A-Za-z0-9_]+)\}`)
+
+// unsafeFileChars matches the characters replaced in tag values used in file
+// names: anything but letters, digits, periods, underscores and hyphens, in
+// any script
+var unsafeFileChars = regexp.MustCompile(`[^\pL\pN._-]+`)
+
+// splitter collects games into chunks and writes each chunk to a file
+type splitter struct {
+	dir      string
+	template string
+	games    int
+	bytes    int
+
+	chunk  []string
+	size   int
+	first  *pgn.Token
+	enc    pgn.Encoding
+	bom    bool
+	number int
+	names  map[string]bool
+}
+
+// splitCommand writes the games of the files to one file per game, or per
+// chunk of -n games or of at most -bytes bytes. Each game is copied byte for
+// byte, in the encoding of the file it came from, and a chunk never mixes
+// files of different encodings. Output files are named from a template whose
+// {Name} fields are the tags of the first game in the file, and {N} its
+// number.
+func splitCommand(args []string) int {
+	flags := flagSet("split", "[-n games | -bytes size] [-o template] [-dir directory] [file...]")
+	games := flags.Int("n", 1, "number of games in each output file")
+	size := flags.Int("bytes", 0, "largest size of each output file in bytes, instead of -n")
+	template := flags.String("o", "{N}.pgn", "output file name template, such as {Date}_{White}_{Black}.pgn")
+	dir := flags.String("dir", ".", "directory to write the output files to")
+	flags.Parse(args)
+
+	if *games < 1 || *size < 0 {
+		flags.Usage()
+		return 2
+	}
+
+	s := &splitter{
+		dir:      *dir,
+		template: *template,
+		games:    *games,
+		bytes:    *size,
+		names:    map[string]bool{},
+	}
+	code := eachInput(flags.Args(), func(in input) (bool, error) {
+		b, text, err := in.decode()
+		if err != nil {
+			return false, err
+		}
+		doc, err := pgn.ParseDocument(text)
+		if err != nil {
+			return false, in.error(err)
+		}
+		enc, bom := pgn.DetectEncoding(b), bytes.HasPrefix(b, bomUTF8)
+		if (enc != s.enc || bom != s.bom) && len(s.chunk) > 0 {
+			if err := s.flush(); err != nil {
+				return false, err
+			}
+		}
+		s.enc, s.bom = enc, bom
+		for _, game := range doc.Games() {
+			if err := s.add(game); err != nil {
+				return false, err
+			}
+		}
+		return true, nil
+	})
+	if err := s.flush(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	return code
+}
+
+// add adds a game node to the current chunk, writing the chunk first if the
+// game does not fit. Sizes are counted in the bytes of the chunk's encoding.
+func (s *splitter) add(game *pgn.Token) error {
+	text := game.Text()
+	size, newline := s.encodedLen(text), s.encodedLen("\n")
+	full := len(s.chunk) >= s.games
+	if s.bytes > 0 {
+		full = s.size+size+newline > s.bytes
+	}
+	if full && len(s.chunk) > 0 {
+		if err := s.flush(); err != nil {
+			return err
+		}
+	}
+	if len(s.chunk) == 0 {
+		s.first = game
+		s.size = len(s.header())
+	}
+	s.chunk = append(s.chunk, text)
+	// games are separated by a blank line
+	s.size += size + 2*newline
+	return nil
+}
+
+// encodedLen returns the number of bytes text takes in the chunk's encoding,
+// not counting a byte order mark
+func (s *splitter) encodedLen(text string) int {
+	b, err := pgn.Encode(text, s.enc)
+	if err != nil {
+		return len(text)
+	}
+	return len(b) - len(s.header())
+}
+
+// header returns the byte order mark the chunk's files start with, if any
+func (s *splitter) header() []byte {
+	switch {
+	case s.enc == pgn.EncodingUTF16LE || s.enc == pgn.EncodingUTF16BE:
+		b, _ := pgn.Encode("", s.enc)
+		return b
+	case s.bom:
+		return bomUTF8
+	}
+	return nil
+}
+
+// flush writes the current chunk
+func (s *splitter) flush() error {
+	if len(s.chunk) == 0 {
+		return nil
+	}
+	s.number++
+	name := filepath.Join(s.dir, s.name())
+	s.names[name] = true
+
+	content, err := pgn.Encode(strings.Join(s.chunk, "\n\n")+"\n", s.enc)
+	if err != nil {
+		return err
+	}
+	if s.bom {
+		content = append(append([]byte{}, bomUTF8...), content...)
+	}
+	s.chunk, s.size = nil, 0
+	return ioutil.WriteFile(name, content, 0644)
+}
+
+// name expands the template for the current chunk. A name already written is
+// made unique with a number.
+func (s *splitter) name() string {
+	name := templateField.ReplaceAllStringFunc(s.template, func(field string) string {
+		tag := field[1 : len(field)-1]
+		if tag == "N" {
+			return strconv.Itoa(s.number)
+		}
+		value, _ := pgn.GameTag(s.first, tag)
+		value = unsafeFileChars.ReplaceAllString(value, "_")
+		if value == "" || strings.Trim(value, "_.") == "" {
+			return "unknown"
+		}
+		return value
+	})
+
+	ext := filepath.Ext(name)
+	base := strings.TrimSuffix(name, ext)
+	for n := 2; s.names[filepath.Join(s.dir, name)]; n++ {
+		name = base + "_" + strconv.Itoa(n) + ext
+	}
+	return name
+}
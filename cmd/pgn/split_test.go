@@ -0,0 +1,108 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+)
+
+func TestSplit(t *testing.T) {
+	games := "1. e4 *\n\n1. d4 *\n\n1. c4 e5 *\n"
+	tagged := "[White \"Caf\xe9\"]\n[Black \"B\"]\n\n1. e4 *\n\n" +
+		"[White \"Caf\xe9\"]\n[Black \"B\"]\n\n1. d4 *\n\n" +
+		"1. c4 *\n"
+	data := []struct {
+		name  string
+		flags []string
+		in    string
+		out   map[string]string
+	}{
+		{
+			name: "One game per file",
+			in:   games,
+			out: map[string]string{
+				"1.pgn": "1. e4 *\n",
+				"2.pgn": "1. d4 *\n",
+				"3.pgn": "1. c4 e5 *\n",
+			},
+		},
+		{
+			name:  "Games per file",
+			flags: []string{"-n", "2"},
+			in:    games,
+			out: map[string]string{
+				"1.pgn": "1. e4 *\n\n1. d4 *\n",
+				"2.pgn": "1. c4 e5 *\n",
+			},
+		},
+		{
+			name:  "Bytes per file",
+			flags: []string{"-bytes", "17"},
+			in:    games,
+			out: map[string]string{
+				"1.pgn": "1. e4 *\n\n1. d4 *\n",
+				"2.pgn": "1. c4 e5 *\n",
+			},
+		},
+		{
+			name:  "Bytes per file counted in UTF-16",
+			flags: []string{"-bytes", "35"},
+			in:    "\xff\xfe1\x00.\x00 \x00e\x004\x00 \x00*\x00\n\x00\n\x001\x00.\x00 \x00d\x004\x00 \x00*\x00\n\x00",
+			out: map[string]string{
+				"1.pgn": "\xff\xfe1\x00.\x00 \x00e\x004\x00 \x00*\x00\n\x00",
+				"2.pgn": "\xff\xfe1\x00.\x00 \x00d\x004\x00 \x00*\x00\n\x00",
+			},
+		},
+		{
+			name:  "Named from tags in Latin-1",
+			flags: []string{"-o", "{White}_{Black}.pgn"},
+			in:    tagged,
+			out: map[string]string{
+				"Café_B.pgn":          "[White \"Caf\xe9\"]\n[Black \"B\"]\n\n1. e4 *\n",
+				"Café_B_2.pgn":        "[White \"Caf\xe9\"]\n[Black \"B\"]\n\n1. d4 *\n",
+				"unknown_unknown.pgn": "1. c4 *\n",
+			},
+		},
+		{
+			name:  "Numbered names",
+			flags: []string{"-n", "2", "-o", "part{N}_{White}.pgn"},
+			in:    tagged,
+			out: map[string]string{
+				"part1_Café.pgn":    "[White \"Caf\xe9\"]\n[Black \"B\"]\n\n1. e4 *\n\n[White \"Caf\xe9\"]\n[Black \"B\"]\n\n1. d4 *\n",
+				"part2_unknown.pgn": "1. c4 *\n",
+			},
+		},
+	}
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			path := writeFile(t, "games.pgn", []byte(test.in))
+			dir := t.TempDir()
+			args := append(append([]string{"-dir", dir}, test.flags...), path)
+			if _, code := run(t, splitCommand, args...); code != 0 {
+				t.Fatalf("Got exit code %d", code)
+			}
+			files, err := ioutil.ReadDir(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(files) != len(test.out) {
+				for _, f := range files {
+					fmt.Println("Got:", f.Name())
+				}
+				t.Fatal("Unexpected files")
+			}
+			for name, exp := range test.out {
+				got, err := ioutil.ReadFile(filepath.Join(dir, name))
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != exp {
+					fmt.Printf("Got: %q\n", got)
+					fmt.Printf("Exp: %q\n", exp)
+					t.Fatalf("Unexpected contents of %s", name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/miketmoore/pgn"
+)
+
+// stats summarizes a collection of games
+type stats struct {
+	games   int
+	plies   int
+	results map[pgn.Result]int
+}
+
+func (s *stats) add(game pgn.Game) {
+	s.games++
+	s.plies += game.Plies()
+	result := game.Termination
+	if result == "" {
+		result = pgn.Result(game.Result())
+	}
+	if result == "" {
+		result = pgn.ResultUnknown
+	}
+	s.results[result]++
+}
+
+func (s stats) print() {
+	fmt.Printf("Games: %d\n", s.games)
+	fmt.Printf("White wins: %d\n", s.results[pgn.ResultWhiteWins])
+	fmt.Printf("Black wins: %d\n", s.results[pgn.ResultBlackWins])
+	fmt.Printf("Draws: %d\n", s.results[pgn.ResultDraw])
+	fmt.Printf("Unknown results: %d\n", s.results[pgn.ResultUnknown])
+	fmt.Printf("Plies: %d\n", s.plies)
+	if s.games > 0 {
+		fmt.Printf("Average plies: %.1f\n", float64(s.plies)/float64(s.games))
+	}
+}
+
+// statsCommand counts the games, results and plies of all the files
+func statsCommand(args []string) int {
+	flags := flagSet("stats", "[file...]")
+	flags.Parse(args)
+
+	s := stats{results: map[pgn.Result]int{}}
+	code := eachInput(flags.Args(), func(in input) (bool, error) {
+		_, unmarshalled, err := in.parse()
+		if err != nil {
+			return false, err
+		}
+		for _, game := range unmarshalled.Games {
+			s.add(game)
+		}
+		return true, nil
+	})
+	s.print()
+	return code
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/miketmoore/pgn"
+)
+
+// tokensCommand prints the tokens the lexer produces for each file, one per
+// line as file:line:col: type value, or only their number with -count, or the
+// concrete syntax tree with -tree
+func tokensCommand(args []string) int {
+	flags := flagSet("tokens", "[-count | -tree] [file...]")
+	count := flags.Bool("count", false, "print only the number of tokens in each file")
+	tree := flags.Bool("tree", false, "print the concrete syntax tree, indented by depth")
+	flags.Parse(args)
+
+	if *tree {
+		return eachInput(flags.Args(), func(in input) (bool, error) {
+			b, err := in.read()
+			if err != nil {
+				return false, err
+			}
+			lexer := pgn.NewLexer(pgn.NewScanner(pgn.Decode(b, pgn.EncodingAuto)))
+			err, root := lexer.TokenizeTree()
+			printTree(in, root, 0)
+			return true, in.error(err)
+		})
+	}
+
+	return eachInput(flags.Args(), func(in input) (bool, error) {
+		b, err := in.read()
+		if err != nil {
+			return false, err
+		}
+		lexer := pgn.NewLexer(pgn.NewScanner(pgn.Decode(b, pgn.EncodingAuto)))
+		err, tokens := lexer.Tokenize()
+		if !*count {
+			// the tokens read before a syntax error are printed with it
+			for _, token := range tokens {
+				fmt.Printf("%s:%d:%d: %s %q\n", in.name, token.Pos.Line, token.Pos.Column, token.Type, token.Value)
+			}
+		}
+		if err != nil {
+			return false, in.error(err)
+		}
+		if *count {
+			fmt.Printf("%s: %d tokens\n", in.name, len(tokens))
+		}
+		return true, nil
+	})
+}
+
+func printTree(in input, token pgn.Token, depth int) {
+	fmt.Printf("%s:%d:%d: %s%s", in.name, token.Pos.Line, token.Pos.Column, strings.Repeat("  ", depth), token.Type)
+	if len(token.Children) == 0 {
+		fmt.Printf(" %q", token.Value)
+	}
+	fmt.Println()
+	for _, child := range token.Children {
+		printTree(in, child, depth+1)
+	}
+}
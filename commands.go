@@ -0,0 +1,245 @@
+package pgn
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Names of the embedded commands with typed fields on Move
+const (
+	CommandClock       = "clk"
+	CommandElapsedTime = "emt"
+	CommandEval        = "eval"
+)
+
+const (
+	ERR_COMMAND_CLOCK = "Expected clock time in the form H:MM:SS"
+	ERR_COMMAND_EVAL  = "Expected evaluation in pawns or #mate, optionally followed by ,depth"
+)
+
+// Command is a command embedded in a comment, such as [%clk 0:03:00], with
+// the name "clk" and the value "0:03:00"
+type Command struct {
+	Name  string
+	Value string
+}
+
+func (c Command) String() string {
+	return "[%" + c.Name + " " + c.Value + "]"
+}
+
+// Eval is an engine evaluation from an [%eval] command, from White's point of
+// view. A mate score has a non-zero Mate, the number of moves to mate, which
+// is negative when Black mates. Depth is zero when not given.
+type Eval struct {
+	Centipawns int
+	Mate       int
+	Depth      int
+}
+
+// IsMate reports whether the evaluation is a forced mate
+func (e Eval) IsMate() bool { return e.Mate != 0 }
+
+// String formats the evaluation as the value of an [%eval] command
+func (e Eval) String() string {
+	s := ""
+	if e.IsMate() {
+		s = "#" + strconv.Itoa(e.Mate)
+	} else {
+		sign := ""
+		cp := e.Centipawns
+		if cp < 0 {
+			sign = "-"
+			cp = -cp
+		}
+		s = fmt.Sprintf("%s%d.%02d", sign, cp/100, cp%100)
+	}
+	if e.Depth > 0 {
+		s = s + "," + strconv.Itoa(e.Depth)
+	}
+	return s
+}
+
+// ParseEval parses the value of an [%eval] command, such as "0.17", "#-3" or
+// "#-3,20"
+func ParseEval(s string) (Eval, error) {
+	e := Eval{}
+
+	if i := strings.Index(s, ","); i >= 0 {
+		depth, err := strconv.Atoi(s[i+1:])
+		if err != nil || depth < 0 {
+			return Eval{}, errors.New(ERR_COMMAND_EVAL)
+		}
+		e.Depth = depth
+		s = s[:i]
+	}
+
+	if strings.HasPrefix(s, "#") {
+		mate, err := strconv.Atoi(s[1:])
+		if err != nil || mate == 0 {
+			return Eval{}, errors.New(ERR_COMMAND_EVAL)
+		}
+		e.Mate = mate
+		return e, nil
+	}
+
+	pawns, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return Eval{}, errors.New(ERR_COMMAND_EVAL)
+	}
+	if pawns < 0 {
+		e.Centipawns = int(pawns*100 - 0.5)
+	} else {
+		e.Centipawns = int(pawns*100 + 0.5)
+	}
+	return e, nil
+}
+
+// ParseClock parses a clock time as written in [%clk] and [%emt] commands,
+// such as "1:59:58" or "0:00:07.3"
+func ParseClock(s string) (time.Duration, error) {
+	parts := strings.Split(s, ":")
+	if len(parts) > 3 {
+		return 0, errors.New(ERR_COMMAND_CLOCK)
+	}
+	d := time.Duration(0)
+	for i, part := range parts {
+		if part == "" {
+			return 0, errors.New(ERR_COMMAND_CLOCK)
+		}
+		if i < len(parts)-1 {
+			n, err := strconv.Atoi(part)
+			if err != nil || n < 0 {
+				return 0, errors.New(ERR_COMMAND_CLOCK)
+			}
+			d = (d + time.Duration(n)) * 60
+			continue
+		}
+		seconds, err := strconv.ParseFloat(part, 64)
+		if err != nil || seconds < 0 {
+			return 0, errors.New(ERR_COMMAND_CLOCK)
+		}
+		d = d*time.Second + time.Duration(seconds*float64(time.Second)+0.5)
+	}
+	return d, nil
+}
+
+// FormatClock formats a clock time as H:MM:SS, adding a decimal fraction of a
+// second only when there is one
+func FormatClock(d time.Duration) string {
+	d = d.Round(time.Millisecond)
+	h := d / time.Hour
+	m := (d % time.Hour) / time.Minute
+	s := (d % time.Minute) / time.Second
+	ms := (d % time.Second) / time.Millisecond
+	out := fmt.Sprintf("%d:%02d:%02d", h, m, s)
+	if ms > 0 {
+		out = out + strings.TrimRight(fmt.Sprintf(".%03d", ms), "0")
+	}
+	return out
+}
+
+// parseCommands splits the text of a comment into its embedded commands and
+// the remaining text
+func parseCommands(comment string) (string, []Command) {
+	commands := []Command{}
+	text := ""
+	rest := comment
+	for {
+		start := strings.Index(rest, "[%")
+		if start < 0 {
+			break
+		}
+		end := strings.Index(rest[start:], "]")
+		if end < 0 {
+			break
+		}
+		end += start
+
+		body := strings.TrimSpace(rest[start+2 : end])
+		name, value := body, ""
+		if i := strings.IndexAny(body, " \t\r\n"); i >= 0 {
+			name, value = body[:i], strings.TrimSpace(body[i+1:])
+		}
+		commands = append(commands, Command{Name: name, Value: value})
+
+		text = text + rest[:start] + " "
+		rest = rest[end+1:]
+	}
+	text = text + rest
+	return strings.Join(strings.Fields(text), " "), commands
+}
+
+// setComment fills the comment text and embedded command fields of m from the
+// text of a comment. Commands with typed fields that fail to parse are kept
+// in Commands as they are.
+func (m *Move) setComment(comment string) {
+	text, commands := parseCommands(comment)
+	if text != "" {
+		if m.Comment != "" {
+			m.Comment = m.Comment + " "
+		}
+		m.Comment = m.Comment + text
+	}
+	for _, c := range commands {
+		switch c.Name {
+		case CommandClock:
+			if d, err := ParseClock(c.Value); err == nil {
+				m.Clock = &d
+				continue
+			}
+		case CommandElapsedTime:
+			if d, err := ParseClock(c.Value); err == nil {
+				m.ElapsedTime = &d
+				continue
+			}
+		case CommandEval:
+			if e, err := ParseEval(c.Value); err == nil {
+				m.Eval = &e
+				continue
+			}
+		case CommandColoredSquares:
+			if a, err := ParseSquareAnnotations(c.Value); err == nil {
+				m.Squares = append(m.Squares, a...)
+				continue
+			}
+		case CommandColoredArrows:
+			if a, err := ParseArrowAnnotations(c.Value); err == nil {
+				m.Arrows = append(m.Arrows, a...)
+				continue
+			}
+		}
+		m.Commands = append(m.Commands, c)
+	}
+}
+
+// comment returns the text of the comment for m, with its typed fields written
+// back as embedded commands, or "" if m has nothing to comment
+func (m Move) comment() string {
+	parts := []string{}
+	if m.Eval != nil {
+		parts = append(parts, Command{Name: CommandEval, Value: m.Eval.String()}.String())
+	}
+	if m.Clock != nil {
+		parts = append(parts, Command{Name: CommandClock, Value: FormatClock(*m.Clock)}.String())
+	}
+	if m.ElapsedTime != nil {
+		parts = append(parts, Command{Name: CommandElapsedTime, Value: FormatClock(*m.ElapsedTime)}.String())
+	}
+	if len(m.Squares) > 0 {
+		parts = append(parts, Command{Name: CommandColoredSquares, Value: formatSquareAnnotations(m.Squares)}.String())
+	}
+	if len(m.Arrows) > 0 {
+		parts = append(parts, Command{Name: CommandColoredArrows, Value: formatArrowAnnotations(m.Arrows)}.String())
+	}
+	for _, c := range m.Commands {
+		parts = append(parts, c.String())
+	}
+	if m.Comment != "" {
+		parts = append(parts, m.Comment)
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,93 @@
+package pgn_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestParseEval(t *testing.T) {
+	data := []struct {
+		in           string
+		out          pgn.Eval
+		errorMessage string
+	}{
+		{in: "0.17", out: pgn.Eval{Centipawns: 17}},
+		{in: "-1.05", out: pgn.Eval{Centipawns: -105}},
+		{in: "0.00", out: pgn.Eval{}},
+		{in: "#3", out: pgn.Eval{Mate: 3}},
+		{in: "#-3,20", out: pgn.Eval{Mate: -3, Depth: 20}},
+		{in: "0.17,18", out: pgn.Eval{Centipawns: 17, Depth: 18}},
+		{in: "abc", errorMessage: pgn.ERR_COMMAND_EVAL},
+		{in: "#0", errorMessage: pgn.ERR_COMMAND_EVAL},
+		{in: "0.17,", errorMessage: pgn.ERR_COMMAND_EVAL},
+	}
+	for _, test := range data {
+		t.Run(test.in, func(t *testing.T) {
+			got, err := pgn.ParseEval(test.in)
+			if test.errorMessage != "" {
+				if err == nil || err.Error() != test.errorMessage {
+					fmt.Println(err)
+					t.Fatal("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				fmt.Println(err)
+				t.Fatal("Unexpected error")
+			}
+			if got != test.out {
+				fmt.Println("Got:", got)
+				fmt.Println("Exp:", test.out)
+				t.Fatal("Unexpected eval")
+			}
+			if got.String() != test.in {
+				fmt.Println("Got:", got.String())
+				t.Fatal("Eval did not format back to its input")
+			}
+		})
+	}
+}
+
+func TestParseClock(t *testing.T) {
+	data := []struct {
+		in           string
+		out          time.Duration
+		errorMessage string
+	}{
+		{in: "0:03:00", out: 3 * time.Minute},
+		{in: "1:59:58", out: time.Hour + 59*time.Minute + 58*time.Second},
+		{in: "0:00:07.3", out: 7*time.Second + 300*time.Millisecond},
+		{in: "0:00:00", out: 0},
+		{in: "0::00", errorMessage: pgn.ERR_COMMAND_CLOCK},
+		{in: "1:2:3:4", errorMessage: pgn.ERR_COMMAND_CLOCK},
+		{in: "a:00:00", errorMessage: pgn.ERR_COMMAND_CLOCK},
+	}
+	for _, test := range data {
+		t.Run(test.in, func(t *testing.T) {
+			got, err := pgn.ParseClock(test.in)
+			if test.errorMessage != "" {
+				if err == nil || err.Error() != test.errorMessage {
+					fmt.Println(err)
+					t.Fatal("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				fmt.Println(err)
+				t.Fatal("Unexpected error")
+			}
+			if got != test.out {
+				fmt.Println("Got:", got)
+				fmt.Println("Exp:", test.out)
+				t.Fatal("Unexpected clock")
+			}
+			if pgn.FormatClock(got) != test.in {
+				fmt.Println("Got:", pgn.FormatClock(got))
+				t.Fatal("Clock did not format back to its input")
+			}
+		})
+	}
+}
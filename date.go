@@ -0,0 +1,160 @@
+package pgn
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Names of the tags that hold a PGNDate
+const (
+	TagEventDate = "EventDate"
+	TagUTCDate   = "UTCDate"
+)
+
+const (
+	ERR_DATE_FORMAT = "Expected date in the form YYYY.MM.DD with ? for unknown digits"
+	ERR_DATE_RANGE  = "Date component out of range"
+)
+
+// PGNDate is a date as written in the Date, EventDate and UTCDate tags, such
+// as "1992.11.04". Any component may be unknown, as in "1992.??.??", and an
+// unknown component is held as zero.
+type PGNDate struct {
+	Year, Month, Day int
+}
+
+// ParseDate parses a date in the PGN form YYYY.MM.DD, where each component is
+// either all digits or all question marks
+func ParseDate(s string) (PGNDate, error) {
+	if len(s) != 10 || s[4] != '.' || s[7] != '.' {
+		return PGNDate{}, errors.New(ERR_DATE_FORMAT)
+	}
+
+	d := PGNDate{}
+	var err error
+	if d.Year, err = parseDateComponent(s[0:4]); err != nil {
+		return PGNDate{}, err
+	}
+	if d.Month, err = parseDateComponent(s[5:7]); err != nil {
+		return PGNDate{}, err
+	}
+	if d.Day, err = parseDateComponent(s[8:10]); err != nil {
+		return PGNDate{}, err
+	}
+
+	if s[0:4] != "????" && d.Year == 0 {
+		return PGNDate{}, errors.New(ERR_DATE_RANGE)
+	}
+	if d.Month > 12 || (s[5:7] != "??" && d.Month == 0) {
+		return PGNDate{}, errors.New(ERR_DATE_RANGE)
+	}
+	if d.Day > d.daysInMonth() || (s[8:10] != "??" && d.Day == 0) {
+		return PGNDate{}, errors.New(ERR_DATE_RANGE)
+	}
+
+	return d, nil
+}
+
+func parseDateComponent(s string) (int, error) {
+	unknown := true
+	for _, r := range s {
+		if r != '?' {
+			unknown = false
+		}
+	}
+	if unknown {
+		return 0, nil
+	}
+	for _, r := range s {
+		if !isDigit(r) {
+			return 0, errors.New(ERR_DATE_FORMAT)
+		}
+	}
+	return strconv.Atoi(s)
+}
+
+// daysInMonth returns the most days the month can have, allowing for 29
+// February when the year is unknown
+func (d PGNDate) daysInMonth() int {
+	switch d.Month {
+	case 0:
+		return 31
+	case 2:
+		if d.Year == 0 {
+			return 29
+		}
+	}
+	return time.Date(d.Year, time.Month(d.Month)+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// String formats the date as YYYY.MM.DD with question marks for unknown
+// components
+func (d PGNDate) String() string {
+	year, month, day := "????", "??", "??"
+	if d.Year != 0 {
+		year = fmt.Sprintf("%04d", d.Year)
+	}
+	if d.Month != 0 {
+		month = fmt.Sprintf("%02d", d.Month)
+	}
+	if d.Day != 0 {
+		day = fmt.Sprintf("%02d", d.Day)
+	}
+	return year + "." + month + "." + day
+}
+
+// IsKnown reports whether every component of the date is known
+func (d PGNDate) IsKnown() bool {
+	return d.Year != 0 && d.Month != 0 && d.Day != 0
+}
+
+// IsUnknown reports whether no component of the date is known
+func (d PGNDate) IsUnknown() bool {
+	return d.Year == 0 && d.Month == 0 && d.Day == 0
+}
+
+// Compare returns -1, 0 or 1 as d is before, the same as, or after o. Years
+// are compared first, then months, then days, and an unknown component sorts
+// before any known one, so "1992.??.??" comes before "1992.01.01".
+func (d PGNDate) Compare(o PGNDate) int {
+	for _, c := range [][2]int{{d.Year, o.Year}, {d.Month, o.Month}, {d.Day, o.Day}} {
+		if c[0] < c[1] {
+			return -1
+		}
+		if c[0] > c[1] {
+			return 1
+		}
+	}
+	return 0
+}
+
+// Time returns the date as midnight UTC. It reports false unless every
+// component is known.
+func (d PGNDate) Time() (time.Time, bool) {
+	if !d.IsKnown() {
+		return time.Time{}, false
+	}
+	return time.Date(d.Year, time.Month(d.Month), d.Day, 0, 0, 0, 0, time.UTC), true
+}
+
+// DateFromTime returns the PGNDate for the calendar day of t
+func DateFromTime(t time.Time) PGNDate {
+	return PGNDate{Year: t.Year(), Month: int(t.Month()), Day: t.Day()}
+}
+
+// DateTag parses the value of a date tag such as Date, EventDate or UTCDate.
+// A missing tag gives a date with every component unknown.
+func (g *Game) DateTag(name string) (PGNDate, error) {
+	value, ok := g.Tag(name)
+	if !ok {
+		return PGNDate{}, nil
+	}
+	return ParseDate(value)
+}
+
+// SetDateTag sets a date tag such as Date, EventDate or UTCDate
+func (g *Game) SetDateTag(name string, d PGNDate) {
+	g.SetTag(name, d.String())
+}
@@ -0,0 +1,113 @@
+package pgn_test
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestParseDate(t *testing.T) {
+	data := []struct {
+		in           string
+		out          pgn.PGNDate
+		errorMessage string
+	}{
+		{in: "1992.11.04", out: pgn.PGNDate{Year: 1992, Month: 11, Day: 4}},
+		{in: "1992.??.??", out: pgn.PGNDate{Year: 1992}},
+		{in: "????.??.??", out: pgn.PGNDate{}},
+		{in: "2000.02.29", out: pgn.PGNDate{Year: 2000, Month: 2, Day: 29}},
+		{in: "????.02.29", out: pgn.PGNDate{Month: 2, Day: 29}},
+		{in: "1992.1.4", errorMessage: pgn.ERR_DATE_FORMAT},
+		{in: "1992/11/04", errorMessage: pgn.ERR_DATE_FORMAT},
+		{in: "19?2.11.04", errorMessage: pgn.ERR_DATE_FORMAT},
+		{in: "1992.13.04", errorMessage: pgn.ERR_DATE_RANGE},
+		{in: "1999.02.29", errorMessage: pgn.ERR_DATE_RANGE},
+		{in: "1992.11.00", errorMessage: pgn.ERR_DATE_RANGE},
+	}
+	for _, test := range data {
+		t.Run(test.in, func(t *testing.T) {
+			got, err := pgn.ParseDate(test.in)
+			if test.errorMessage != "" {
+				if err == nil || err.Error() != test.errorMessage {
+					fmt.Println(err)
+					t.Fatal("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				fmt.Println(err)
+				t.Fatal("Unexpected error")
+			}
+			if got != test.out {
+				fmt.Println("Got:", got)
+				fmt.Println("Exp:", test.out)
+				t.Fatal("Unexpected date")
+			}
+			if got.String() != test.in {
+				fmt.Println("Got:", got.String())
+				t.Fatal("Date did not format back to its input")
+			}
+		})
+	}
+}
+
+func TestPGNDateCompare(t *testing.T) {
+	in := []string{"1992.11.04", "????.??.??", "1992.??.??", "1972.07.11", "1992.11.??", "1992.01.01"}
+	dates := []pgn.PGNDate{}
+	for _, s := range in {
+		d, err := pgn.ParseDate(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		dates = append(dates, d)
+	}
+	sort.Slice(dates, func(i, j int) bool { return dates[i].Compare(dates[j]) < 0 })
+
+	exp := []string{"????.??.??", "1972.07.11", "1992.??.??", "1992.01.01", "1992.11.??", "1992.11.04"}
+	for i, d := range dates {
+		if d.String() != exp[i] {
+			fmt.Println("Got:", dates)
+			fmt.Println("Exp:", exp)
+			t.Fatal("Unexpected order")
+		}
+	}
+}
+
+func TestPGNDateTime(t *testing.T) {
+	d := pgn.PGNDate{Year: 1992, Month: 11, Day: 4}
+	got, ok := d.Time()
+	if !ok || !got.Equal(time.Date(1992, time.November, 4, 0, 0, 0, 0, time.UTC)) {
+		t.Fatal("Unexpected time")
+	}
+	if _, ok := (pgn.PGNDate{Year: 1992}).Time(); ok {
+		t.Fatal("Expected a partial date to have no time")
+	}
+}
+
+func TestGameDateTag(t *testing.T) {
+	game := pgn.Game{
+		TagPairs: []pgn.TagPair{
+			pgn.TagPair{Name: "Date", Value: "1992.11.04"},
+			pgn.TagPair{Name: "EventDate", Value: "1992.??.??"},
+		},
+	}
+	date, err := game.DateTag(pgn.TagDate)
+	if err != nil || date != (pgn.PGNDate{Year: 1992, Month: 11, Day: 4}) {
+		t.Fatal("Unexpected Date")
+	}
+	eventDate, err := game.DateTag(pgn.TagEventDate)
+	if err != nil || eventDate != (pgn.PGNDate{Year: 1992}) {
+		t.Fatal("Unexpected EventDate")
+	}
+	utcDate, err := game.DateTag(pgn.TagUTCDate)
+	if err != nil || !utcDate.IsUnknown() {
+		t.Fatal("Expected missing UTCDate to be unknown")
+	}
+	game.SetDateTag(pgn.TagUTCDate, pgn.PGNDate{Year: 1992, Month: 11, Day: 5})
+	if value, _ := game.Tag(pgn.TagUTCDate); value != "1992.11.05" {
+		t.Fatal("Unexpected UTCDate")
+	}
+}
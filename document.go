@@ -0,0 +1,218 @@
+package pgn
+
+import (
+	"strings"
+)
+
+// Document is a PGN file held as its concrete syntax tree, so that it can be
+// edited and written back with everything that was not edited, whitespace,
+// comments and line breaks included, kept byte for byte. Use Marshal or
+// MarshalExport instead to write games in a standard layout.
+type Document struct {
+	Root Token
+}
+
+// ParseDocument reads a PGN file into a Document. String returns in unchanged
+// until the document is edited.
+func ParseDocument(in string) (*Document, error) {
+	lexer := NewLexer(NewScanner(in))
+	err, root := lexer.TokenizeTree()
+	if err != nil {
+		return nil, err
+	}
+	return &Document{Root: root}, nil
+}
+
+// String writes the document
+func (d *Document) String() string {
+	return d.Root.Text()
+}
+
+// Games returns the game nodes of the document in order. Each of the methods
+// that take the index of a game looks through the whole document for it, so
+// a program going through every game should range over Games and use
+// UnmarshalGame and GameTag on the nodes instead.
+func (d *Document) Games() []*Token {
+	games := []*Token{}
+	for i := range d.Root.Children {
+		if d.Root.Children[i].Type == TokenGame {
+			games = append(games, &d.Root.Children[i])
+		}
+	}
+	return games
+}
+
+// Len returns the number of games in the document
+func (d *Document) Len() int {
+	return len(d.Games())
+}
+
+// GameText returns the source text of the i-th game, from its first tag pair
+// to its result
+func (d *Document) GameText(i int) string {
+	return d.Games()[i].Text()
+}
+
+// Game unmarshals the i-th game of the document
+func (d *Document) Game(i int) (Game, error) {
+	return UnmarshalGame(d.Games()[i])
+}
+
+// Tag returns the value of the first tag pair with the given name in the i-th
+// game
+func (d *Document) Tag(i int, name string) (string, bool) {
+	return GameTag(d.Games()[i], name)
+}
+
+// UnmarshalGame unmarshals a game node of a document
+func UnmarshalGame(game *Token) (Game, error) {
+	var unmarshalled PGN
+	if err := Unmarshal(game.Text(), &unmarshalled); err != nil {
+		return Game{}, err
+	}
+	if len(unmarshalled.Games) == 0 {
+		return Game{}, nil
+	}
+	return unmarshalled.Games[0], nil
+}
+
+// GameTag returns the value of the first tag pair with the given name in a
+// game node of a document
+func GameTag(game *Token, name string) (string, bool) {
+	tagPair := findTagPair(game, name)
+	if tagPair == nil {
+		return "", false
+	}
+	return tagPairValue(*tagPair), true
+}
+
+// SetTag sets the value of the first tag pair with the given name in the i-th
+// game, changing only its string. A new tag pair is added on its own line
+// after the last one, using the line break the document already uses.
+func (d *Document) SetTag(i int, name, value string) {
+	game := d.Games()[i]
+	if tagPair := findTagPair(game, name); tagPair != nil {
+		for j, child := range tagPair.Children {
+			if child.Type == TokenString {
+				tagPair.Children[j].Value = quoteString(value)
+			}
+		}
+		return
+	}
+
+	tagPair := node(TokenTagPair, []Token{
+		{Type: TokenTagPairOpen, Value: "["},
+		{Type: TokenTagName, Value: name},
+		{Type: TokenWhitespace, Value: " "},
+		{Type: TokenString, Value: quoteString(value)},
+		{Type: TokenTagPairClose, Value: "]"},
+	})
+	lineBreak := d.lineBreak()
+
+	section := tagSection(game)
+	if section == nil || len(section.Children) == 0 {
+		// a game without tags gets a tag section and a blank line before its
+		// movetext
+		game.Children = append([]Token{
+			node(TokenTagSection, []Token{tagPair}),
+			{Type: TokenWhitespace, Value: lineBreak + lineBreak},
+		}, withoutEmptySection(game.Children)...)
+		return
+	}
+	section.Children = append(section.Children, Token{Type: TokenWhitespace, Value: lineBreak}, tagPair)
+}
+
+// DeleteTag removes the first tag pair with the given name from the i-th game,
+// along with the line break that separated it from its neighbour, and reports
+// whether there was one
+func (d *Document) DeleteTag(i int, name string) bool {
+	game := d.Games()[i]
+	section := tagSection(game)
+	if section == nil {
+		return false
+	}
+	for j, child := range section.Children {
+		if child.Type != TokenTagPair || tagPairName(child) != name {
+			continue
+		}
+		start, end := j, j+1
+		if end < len(section.Children) && section.Children[end].Type == TokenWhitespace {
+			end++
+		} else if start > 0 && section.Children[start-1].Type == TokenWhitespace {
+			start--
+		}
+		section.Children = append(section.Children[:start], section.Children[end:]...)
+
+		if len(section.Children) == 0 {
+			// the blank line after the tag section goes with it
+			game.Children = withoutEmptySection(game.Children)
+			if len(game.Children) > 0 && game.Children[0].Type == TokenWhitespace {
+				game.Children = game.Children[1:]
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// lineBreak returns the line break the document uses, CR LF or LF
+func (d *Document) lineBreak() string {
+	if strings.Contains(d.String(), "\r\n") {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+func findTagPair(game *Token, name string) *Token {
+	section := tagSection(game)
+	if section == nil {
+		return nil
+	}
+	for i, child := range section.Children {
+		if child.Type == TokenTagPair && tagPairName(child) == name {
+			return &section.Children[i]
+		}
+	}
+	return nil
+}
+
+func tagSection(game *Token) *Token {
+	for i, child := range game.Children {
+		if child.Type == TokenTagSection {
+			return &game.Children[i]
+		}
+	}
+	return nil
+}
+
+func withoutEmptySection(children []Token) []Token {
+	kept := []Token{}
+	for _, child := range children {
+		if child.Type != TokenTagSection || len(child.Children) > 0 {
+			kept = append(kept, child)
+		}
+	}
+	return kept
+}
+
+func tagPairName(tagPair Token) string {
+	for _, child := range tagPair.Children {
+		if child.Type == TokenTagName {
+			return child.Value
+		}
+	}
+	return ""
+}
+
+// tagPairValue returns the value of the tag pair's string, without its quotes
+// and escapes
+func tagPairValue(tagPair Token) string {
+	for _, child := range tagPair.Children {
+		if child.Type == TokenString {
+			lexer := NewLexer(NewScanner(child.Value))
+			_, value := lexer.readString()
+			return value
+		}
+	}
+	return ""
+}
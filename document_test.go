@@ -0,0 +1,123 @@
+package pgn_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestDocumentRoundTrip(t *testing.T) {
+	in := "% hand edited\r\n[Event \"F/S\"]\r\n[White  \"Fischer\" ]\r\n\r\n" +
+		"1.e4   e5 {Solid.\r\nVery.} 2. Nf3 (2. f4 $1) 2... Nc6 ; note\r\n1-0\r\n\r\n\r\n" +
+		"1. d4 *"
+	doc, err := pgn.ParseDocument(in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := doc.String(); got != in {
+		fmt.Printf("Got: %q\n", got)
+		fmt.Printf("Exp: %q\n", in)
+		t.Fatal("Unexpected document")
+	}
+	if doc.Len() != 2 {
+		fmt.Println("Got:", doc.Len())
+		t.Fatal("Unexpected total games")
+	}
+	if got := doc.GameText(1); got != "1. d4 *" {
+		fmt.Printf("Got: %q\n", got)
+		t.Fatal("Unexpected game text")
+	}
+	game, err := doc.Game(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.Plies() != 1 || game.Termination != pgn.ResultUnknown {
+		fmt.Println("Got:", game)
+		t.Fatal("Unexpected game")
+	}
+
+	games := doc.Games()
+	if len(games) != 2 || games[1].Text() != "1. d4 *" {
+		fmt.Println("Got:", len(games))
+		t.Fatal("Unexpected game nodes")
+	}
+	if white, ok := pgn.GameTag(games[0], "White"); !ok || white != "Fischer" {
+		fmt.Println("Got:", white, ok)
+		t.Fatal("Unexpected tag")
+	}
+	if _, ok := pgn.GameTag(games[1], "White"); ok {
+		t.Fatal("Expected no tag")
+	}
+	game, err = pgn.UnmarshalGame(games[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if game.Plies() != 4 || game.Termination != pgn.ResultWhiteWins {
+		fmt.Println("Got:", game)
+		t.Fatal("Unexpected game")
+	}
+}
+
+func TestDocumentEdit(t *testing.T) {
+	data := []struct {
+		name string
+		in   string
+		edit func(doc *pgn.Document)
+		out  string
+	}{
+		{
+			name: "Set existing tag",
+			in:   "[Event  \"x\" ]\n[White \"a\"]\n\n1.e4  e5 *\n",
+			edit: func(doc *pgn.Document) { doc.SetTag(0, "Event", "Say \"hi\"") },
+			out:  "[Event  \"Say \\\"hi\\\"\" ]\n[White \"a\"]\n\n1.e4  e5 *\n",
+		},
+		{
+			name: "Add tag",
+			in:   "[Event \"x\"]\r\n\r\n1.e4  e5 *\r\n",
+			edit: func(doc *pgn.Document) { doc.SetTag(0, "Site", "?") },
+			out:  "[Event \"x\"]\r\n[Site \"?\"]\r\n\r\n1.e4  e5 *\r\n",
+		},
+		{
+			name: "Add tag to game without tags",
+			in:   "[Event \"x\"]\n\n1. e4 *\n\n1. d4 *\n",
+			edit: func(doc *pgn.Document) { doc.SetTag(1, "Event", "y") },
+			out:  "[Event \"x\"]\n\n1. e4 *\n\n[Event \"y\"]\n\n1. d4 *\n",
+		},
+		{
+			name: "Delete tags",
+			in:   "[Event \"x\"]\n[Site \"y\"]\n[Date \"z\"]\n\n1. e4 *\n",
+			edit: func(doc *pgn.Document) {
+				doc.DeleteTag(0, "Date")
+				doc.DeleteTag(0, "Event")
+			},
+			out: "[Site \"y\"]\n\n1. e4 *\n",
+		},
+		{
+			name: "Delete last tag",
+			in:   "[Event \"x\"]\n\n1. e4 *\n",
+			edit: func(doc *pgn.Document) { doc.DeleteTag(0, "Event") },
+			out:  "1. e4 *\n",
+		},
+	}
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := pgn.ParseDocument(test.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			test.edit(doc)
+			if got := doc.String(); got != test.out {
+				fmt.Printf("Got: %q\n", got)
+				fmt.Printf("Exp: %q\n", test.out)
+				t.Fatal("Unexpected document")
+			}
+		})
+	}
+
+	doc, _ := pgn.ParseDocument("[Event \"a \\\\ b\"]\n\n*")
+	if value, ok := doc.Tag(0, "Event"); !ok || value != "a \\ b" {
+		fmt.Println("Got:", value, ok)
+		t.Fatal("Unexpected tag value")
+	}
+}
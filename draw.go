@@ -0,0 +1,257 @@
+package pgn
+
+import (
+	"fmt"
+)
+
+// DrawRule is a rule of chess under which a game is drawn
+type DrawRule int
+
+const (
+	// DrawThreefoldRepetition lets a player claim a draw when the same
+	// position occurs for the third time
+	DrawThreefoldRepetition DrawRule = iota
+	// DrawFivefoldRepetition draws the game when the same position occurs
+	// for the fifth time
+	DrawFivefoldRepetition
+	// DrawFiftyMoves lets a player claim a draw after fifty moves by each
+	// side without a pawn move or capture
+	DrawFiftyMoves
+	// DrawSeventyFiveMoves draws the game after seventy-five moves by each
+	// side without a pawn move or capture, unless the last move mates
+	DrawSeventyFiveMoves
+	// DrawInsufficientMaterial draws the game when neither side has the
+	// pieces to mate
+	DrawInsufficientMaterial
+)
+
+func (r DrawRule) String() string {
+	switch r {
+	case DrawThreefoldRepetition:
+		return "threefold repetition"
+	case DrawFivefoldRepetition:
+		return "fivefold repetition"
+	case DrawFiftyMoves:
+		return "50-move rule"
+	case DrawSeventyFiveMoves:
+		return "75-move rule"
+	case DrawInsufficientMaterial:
+		return "insufficient material"
+	}
+	return ""
+}
+
+// Automatic reports whether the rule ends the game by itself, rather than
+// when a player claims the draw
+func (r DrawRule) Automatic() bool {
+	return r != DrawThreefoldRepetition && r != DrawFiftyMoves
+}
+
+// Draw is the point in a game where a draw rule first applied: Ply is the ply
+// after which the draw could be claimed, or after which the game was drawn.
+// Ply has Index 0 when the rule applies to the starting position.
+type Draw struct {
+	Rule DrawRule
+	Ply  Ply
+}
+
+func (d Draw) String() string {
+	if d.Ply.Index == 0 {
+		return d.Rule.String() + " in the starting position"
+	}
+	return d.Rule.String() + " after " + d.Ply.Numbered()
+}
+
+// InsufficientMaterial reports whether neither side has the pieces to mate
+// by any series of legal moves: king against king, king and a bishop or a
+// knight against king, or kings and bishops that all stand on squares of the
+// same colour
+func (p *Position) InsufficientMaterial() bool {
+	minors, knights := 0, 0
+	bishopSquares := [2]bool{}
+	for sq, c := range p.board {
+		switch upper(c) {
+		case 'P', 'R', 'Q':
+			return false
+		case 'N':
+			minors++
+			knights++
+		case 'B':
+			minors++
+			bishopSquares[(fileOf(sq)+rankOf(sq))%2] = true
+		}
+	}
+	if minors <= 1 {
+		return true
+	}
+	return knights == 0 && !(bishopSquares[0] && bishopSquares[1])
+}
+
+// Draws replays the game and returns the first point at which each draw rule
+// applied, in the order they arose. Repetitions compare positions by their
+// Key. The moves after an illegal move are not replayed, and its *MoveError
+// is returned with the draws found before it.
+func (g *Game) Draws() ([]Draw, error) {
+	draws := []Draw{}
+	found := map[DrawRule]bool{}
+	add := func(rule DrawRule, ply Ply) {
+		if !found[rule] {
+			found[rule] = true
+			draws = append(draws, Draw{Rule: rule, Ply: ply})
+		}
+	}
+
+	seen := map[PositionKey]int{}
+	check := func(ply Ply) {
+		p := &ply.Position
+		seen[p.Key()]++
+		switch n := seen[p.Key()]; {
+		case n >= 5:
+			add(DrawFivefoldRepetition, ply)
+			fallthrough
+		case n >= 3:
+			add(DrawThreefoldRepetition, ply)
+		}
+		if p.HalfmoveClock >= 100 {
+			add(DrawFiftyMoves, ply)
+		}
+		if p.HalfmoveClock >= 150 && !p.IsCheckmate() {
+			add(DrawSeventyFiveMoves, ply)
+		}
+		if p.InsufficientMaterial() {
+			add(DrawInsufficientMaterial, ply)
+		}
+	}
+
+	start, err := g.StartingPosition()
+	if err != nil {
+		return draws, err
+	}
+	check(Ply{MoveNumber: start.FullmoveNumber, Color: start.Turn, Position: start})
+	err = g.Replay(func(ply Ply) error {
+		check(ply)
+		return nil
+	})
+	return draws, err
+}
+
+// ValidateDraws audits the result of a game against the draw rules. A game
+// that goes on, or is won, after an automatic draw is an error. A game given
+// as drawn with no draw rule applying at its end is reported as a warning,
+// as it may have been drawn by agreement or by a claim the rules do not see.
+// A repetition or fifty moves earlier in the game do not count, as the claim
+// has to be made in the position where the rule applies, but an automatic
+// draw does.
+func ValidateDraws(g *Game) []Issue {
+	issues := []Issue{}
+	draws, err := g.Draws()
+	if err != nil {
+		// illegal moves are reported by ValidateMoves
+		return issues
+	}
+
+	plies := g.Plies()
+	drawn := false
+	for _, d := range draws {
+		if !d.Rule.Automatic() {
+			continue
+		}
+		drawn = true
+		if d.Ply.Index < plies {
+			issues = append(issues, Issue{
+				Severity: SeverityError,
+				Message:  "game continues after it was drawn by " + d.String(),
+				Pos:      d.Ply.Move.Pos,
+			})
+		} else if g.result() == ResultWhiteWins || g.result() == ResultBlackWins {
+			issues = append(issues, Issue{
+				Severity: SeverityError,
+				Tag:      TagResult,
+				Message:  fmt.Sprintf("result %s but the game was drawn by %s", g.result(), d),
+				Pos:      g.tagPos(TagResult),
+			})
+		}
+	}
+	if g.result() == ResultDraw && !drawn && !g.drawnAtEnd() {
+		issues = append(issues, Issue{
+			Severity: SeverityWarning,
+			Tag:      TagResult,
+			Message:  "game is drawn but no draw rule applies",
+			Pos:      g.tagPos(TagResult),
+		})
+	}
+	return issues
+}
+
+// drawnAtEnd reports whether a draw rule applies to the final position of the
+// game: it has occurred for the third time, the fifty moves have been played,
+// neither side can mate, or the side to move is stalemated
+func (g *Game) drawnAtEnd() bool {
+	final, err := g.FinalPosition()
+	if err != nil {
+		return false
+	}
+	if final.HalfmoveClock >= 100 || final.InsufficientMaterial() || final.IsStalemate() {
+		return true
+	}
+	return g.occurrences(final.Key()) >= 3
+}
+
+// occurrences counts the times a position occurs in the game, the starting
+// position included
+func (g *Game) occurrences(key PositionKey) int {
+	n := 0
+	if start, err := g.StartingPosition(); err == nil && start.Key() == key {
+		n++
+	}
+	g.Replay(func(ply Ply) error {
+		if ply.Position.Key() == key {
+			n++
+		}
+		return nil
+	})
+	return n
+}
+
+// AnnotateDraws adds a comment to the move of each ply where a draw rule
+// first applied, such as "Draw can be claimed by threefold repetition"
+func (g *Game) AnnotateDraws() error {
+	draws, err := g.Draws()
+	for _, d := range draws {
+		text := "Draw can be claimed by " + d.Rule.String()
+		if d.Rule.Automatic() {
+			text = "Drawn by " + d.Rule.String()
+		}
+		if d.Ply.Index == 0 {
+			g.Comment = joinComment(g.Comment, text)
+			continue
+		}
+		if m := g.move(d.Ply); m != nil {
+			m.setComment(text)
+		}
+	}
+	return err
+}
+
+// move returns the move of a replayed ply in the movetext
+func (g *Game) move(ply Ply) *Move {
+	for i := range g.Movetext {
+		if g.Movetext[i].Num != ply.MoveNumber {
+			continue
+		}
+		if ply.Color == White {
+			return &g.Movetext[i].White
+		}
+		return &g.Movetext[i].Black
+	}
+	return nil
+}
+
+// result returns the result of the game from its termination marker, or its
+// Result tag when the movetext has none
+func (g *Game) result() Result {
+	if g.Termination != "" {
+		return g.Termination
+	}
+	return Result(g.Result())
+}
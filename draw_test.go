@@ -0,0 +1,153 @@
+package pgn_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestInsufficientMaterial(t *testing.T) {
+	data := []struct {
+		fen          string
+		insufficient bool
+	}{
+		{"4k3/8/8/8/8/8/8/4K3 w - - 0 1", true},
+		{"4k3/8/8/8/8/8/8/4KN2 w - - 0 1", true},
+		{"4k3/8/8/8/8/8/8/4KB2 w - - 0 1", true},
+		{"4kb2/8/8/8/8/8/8/2B1K3 w - - 0 1", true},
+		{"4k3/8/8/8/8/8/8/2B1KB2 w - - 0 1", false},
+		{"4kn2/8/8/8/8/8/8/4KN2 w - - 0 1", false},
+		{"4k3/8/8/8/8/8/8/4KNN1 w - - 0 1", false},
+		{"4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", false},
+		{"4k3/8/8/8/8/8/8/4K2R w - - 0 1", false},
+	}
+	for _, test := range data {
+		t.Run(test.fen, func(t *testing.T) {
+			p, err := pgn.ParseFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.InsufficientMaterial(); got != test.insufficient {
+				fmt.Println("Got:", got)
+				fmt.Println("Exp:", test.insufficient)
+				t.Fatal("Unexpected insufficient material")
+			}
+		})
+	}
+}
+
+func TestDraws(t *testing.T) {
+	shuffle := "1. Nf3 Nf6 2. Ng1 Ng8 3. Nf3 Nf6 4. Ng1 Ng8 "
+	data := []struct {
+		name string
+		in   string
+		exp  []string
+	}{
+		{"None", "1. e4 e5 2. Nf3 Nc6 1/2-1/2", []string{}},
+		{"Threefold", shuffle + "1/2-1/2", []string{
+			"threefold repetition after 4... Ng8",
+		}},
+		{"Fivefold", shuffle + "5. Nf3 Nf6 6. Ng1 Ng8 7. Nf3 Nf6 8. Ng1 Ng8 1/2-1/2", []string{
+			"threefold repetition after 4... Ng8",
+			"fivefold repetition after 8... Ng8",
+		}},
+		{"Fifty moves", "[SetUp \"1\"]\n[FEN \"4k3/8/8/8/8/8/8/R3K3 w - - 98 80\"]\n\n80. Ra2 Kd8 81. Ra3 1/2-1/2", []string{
+			"50-move rule after 80... Kd8",
+		}},
+		{"Seventy-five moves", "[SetUp \"1\"]\n[FEN \"4k3/8/8/8/8/8/8/R3K3 w - - 149 120\"]\n\n120. Ra2 1/2-1/2", []string{
+			"50-move rule in the starting position",
+			"75-move rule after 120. Ra2",
+		}},
+		{"Checkmate on the 75th move", "[SetUp \"1\"]\n[FEN \"4k3/R7/8/8/8/8/8/4K2R w - - 149 120\"]\n\n120. Rh8# 1-0", []string{
+			"50-move rule in the starting position",
+		}},
+		{"Insufficient material", "[SetUp \"1\"]\n[FEN \"4k3/8/8/8/8/8/3n4/4KB2 w - - 0 1\"]\n\n1. Kxd2 1/2-1/2", []string{
+			"insufficient material after 1. Kxd2",
+		}},
+	}
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			var unmarshalled pgn.PGN
+			if err := pgn.Unmarshal(test.in, &unmarshalled); err != nil {
+				t.Fatal(err)
+			}
+			draws, err := unmarshalled.Games[0].Draws()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, d := range draws {
+				got = append(got, d.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(test.exp, "\n") {
+				fmt.Println("Got:", got)
+				fmt.Println("Exp:", test.exp)
+				t.Fatal("Unexpected draws")
+			}
+		})
+	}
+}
+
+func TestValidateDraws(t *testing.T) {
+	shuffle := "1. Nf3 Nf6 2. Ng1 Ng8 3. Nf3 Nf6 4. Ng1 Ng8 "
+	fivefold := shuffle + "5. Nf3 Nf6 6. Ng1 Ng8 7. Nf3 Nf6 8. Ng1 Ng8 "
+	data := []struct {
+		name string
+		in   string
+		exp  []string
+	}{
+		{"Claimable draw", shuffle + "1/2-1/2", []string{}},
+		{"Decisive after a claimable draw", shuffle + "5. e4 e5 1-0", []string{}},
+		{"Agreed draw", "1. e4 e5 1/2-1/2", []string{
+			"warning: Result: game is drawn but no draw rule applies",
+		}},
+		{"Drawn after a repetition has passed", shuffle + "5. e4 e5 6. d4 d5 1/2-1/2", []string{
+			"warning: Result: game is drawn but no draw rule applies",
+		}},
+		{"Stalemate", "[SetUp \"1\"]\n[FEN \"7k/8/5K2/6Q1/8/8/8/8 w - - 0 1\"]\n\n1. Qg6 1/2-1/2", []string{}},
+		{"Decisive after an automatic draw", fivefold + "1-0", []string{
+			"error: Result: result 1-0 but the game was drawn by fivefold repetition after 8... Ng8",
+		}},
+		{"Play after an automatic draw", fivefold + "9. e4 1/2-1/2", []string{
+			"error: game continues after it was drawn by fivefold repetition after 8... Ng8",
+		}},
+	}
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			var unmarshalled pgn.PGN
+			if err := pgn.Unmarshal(test.in, &unmarshalled); err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, issue := range pgn.ValidateDraws(&unmarshalled.Games[0]) {
+				got = append(got, issue.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(test.exp, "\n") {
+				fmt.Println("Got:", got)
+				fmt.Println("Exp:", test.exp)
+				t.Fatal("Unexpected issues")
+			}
+		})
+	}
+}
+
+func TestAnnotateDraws(t *testing.T) {
+	in := "1. Nf3 Nf6 2. Ng1 Ng8 3. Nf3 Nf6 4. Ng1 Ng8 1/2-1/2"
+	var unmarshalled pgn.PGN
+	if err := pgn.Unmarshal(in, &unmarshalled); err != nil {
+		t.Fatal(err)
+	}
+	game := &unmarshalled.Games[0]
+	if err := game.AnnotateDraws(); err != nil {
+		t.Fatal(err)
+	}
+	got := game.Movetext[3].Black.Comment
+	exp := "Draw can be claimed by threefold repetition"
+	if got != exp {
+		fmt.Println("Got:", got)
+		fmt.Println("Exp:", exp)
+		t.Fatal("Unexpected comment")
+	}
+}
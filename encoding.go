@@ -0,0 +1,187 @@
+package pgn
+
+import (
+	"bytes"
+	"errors"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const ERR_ENCODE = "Character cannot be written in the encoding"
+
+// Encoding is the character encoding of PGN input
+type Encoding int
+
+const (
+	// EncodingAuto detects the encoding with DetectEncoding
+	EncodingAuto Encoding = iota
+	EncodingUTF8
+	EncodingUTF16LE
+	EncodingUTF16BE
+	// EncodingLatin1 is ISO 8859-1
+	EncodingLatin1
+	EncodingWindows1252
+)
+
+var (
+	bomUTF8    = []byte{0xEF, 0xBB, 0xBF}
+	bomUTF16LE = []byte{0xFF, 0xFE}
+	bomUTF16BE = []byte{0xFE, 0xFF}
+)
+
+// windows1252 maps the bytes 0x80 to 0x9F, where Windows-1252 differs from
+// Latin-1. The five bytes Windows-1252 leaves undefined keep their Latin-1
+// meaning.
+var windows1252 = [32]rune{
+	'€', '\u0081', '‚', 'ƒ', '„', '…', '†', '‡',
+	'ˆ', '‰', 'Š', '‹', 'Œ', '\u008D', 'Ž', '\u008F',
+	'\u0090', '‘', '’', '“', '”', '•', '–', '—',
+	'˜', '™', 'š', '›', 'œ', '\u009D', 'ž', 'Ÿ',
+}
+
+func (e Encoding) String() string {
+	switch e {
+	case EncodingAuto:
+		return "auto"
+	case EncodingUTF8:
+		return "UTF-8"
+	case EncodingUTF16LE:
+		return "UTF-16LE"
+	case EncodingUTF16BE:
+		return "UTF-16BE"
+	case EncodingLatin1:
+		return "ISO-8859-1"
+	case EncodingWindows1252:
+		return "Windows-1252"
+	}
+	return "unknown"
+}
+
+// DetectEncoding guesses the encoding of in. A byte order mark wins; without
+// one, valid UTF-8 is taken as UTF-8. Otherwise the input is assumed to be
+// Windows-1252 if it uses any of the bytes 0x80 to 0x9F, which are control
+// characters in Latin-1 but punctuation in Windows-1252, and Latin-1 if not.
+func DetectEncoding(in []byte) Encoding {
+	switch {
+	case bytes.HasPrefix(in, bomUTF8):
+		return EncodingUTF8
+	case bytes.HasPrefix(in, bomUTF16LE):
+		return EncodingUTF16LE
+	case bytes.HasPrefix(in, bomUTF16BE):
+		return EncodingUTF16BE
+	case utf8.Valid(in):
+		return EncodingUTF8
+	}
+	for _, b := range in {
+		if b >= 0x80 && b <= 0x9F {
+			return EncodingWindows1252
+		}
+	}
+	return EncodingLatin1
+}
+
+// Decode transcodes in from the given encoding to a UTF-8 string, dropping
+// any byte order mark. EncodingAuto detects the encoding first.
+func Decode(in []byte, enc Encoding) string {
+	if enc == EncodingAuto {
+		enc = DetectEncoding(in)
+	}
+	switch enc {
+	case EncodingUTF16LE:
+		return decodeUTF16(bytes.TrimPrefix(in, bomUTF16LE), false)
+	case EncodingUTF16BE:
+		return decodeUTF16(bytes.TrimPrefix(in, bomUTF16BE), true)
+	case EncodingLatin1:
+		return DecodeLatin1(in)
+	case EncodingWindows1252:
+		return DecodeWindows1252(in)
+	}
+	return string(bytes.TrimPrefix(in, bomUTF8))
+}
+
+// DecodeLatin1 decodes ISO 8859-1 (Latin-1) text to a UTF-8 string. Every
+// Latin-1 byte maps to the Unicode code point of the same value.
+func DecodeLatin1(in []byte) string {
+	buf := make([]byte, 0, len(in))
+	for _, b := range in {
+		buf = utf8.AppendRune(buf, rune(b))
+	}
+	return string(buf)
+}
+
+// DecodeWindows1252 decodes Windows-1252 text to a UTF-8 string
+func DecodeWindows1252(in []byte) string {
+	buf := make([]byte, 0, len(in))
+	for _, b := range in {
+		r := rune(b)
+		if b >= 0x80 && b <= 0x9F {
+			r = windows1252[b-0x80]
+		}
+		buf = utf8.AppendRune(buf, r)
+	}
+	return string(buf)
+}
+
+func decodeUTF16(in []byte, bigEndian bool) string {
+	units := make([]uint16, 0, len(in)/2)
+	for i := 0; i+1 < len(in); i += 2 {
+		if bigEndian {
+			units = append(units, uint16(in[i])<<8|uint16(in[i+1]))
+		} else {
+			units = append(units, uint16(in[i+1])<<8|uint16(in[i]))
+		}
+	}
+	return string(utf16.Decode(units))
+}
+
+// Encode transcodes s from UTF-8 to the given encoding, the reverse of Decode.
+// UTF-16 is written with a byte order mark, which DetectEncoding needs to
+// recognise it. A character that Latin-1 or Windows-1252 cannot represent is
+// an error. EncodingAuto and EncodingUTF8 return s unchanged.
+func Encode(s string, enc Encoding) ([]byte, error) {
+	switch enc {
+	case EncodingUTF16LE, EncodingUTF16BE:
+		bigEndian := enc == EncodingUTF16BE
+		out := append([]byte{}, bomUTF16LE...)
+		if bigEndian {
+			out = append([]byte{}, bomUTF16BE...)
+		}
+		for _, unit := range utf16.Encode([]rune(s)) {
+			if bigEndian {
+				out = append(out, byte(unit>>8), byte(unit))
+			} else {
+				out = append(out, byte(unit), byte(unit>>8))
+			}
+		}
+		return out, nil
+	case EncodingLatin1, EncodingWindows1252:
+		out := make([]byte, 0, len(s))
+		for _, r := range s {
+			b, ok := encodeByte(r, enc)
+			if !ok {
+				return nil, errors.New(ERR_ENCODE)
+			}
+			out = append(out, b)
+		}
+		return out, nil
+	}
+	return []byte(s), nil
+}
+
+// encodeByte returns the Latin-1 or Windows-1252 byte for r
+func encodeByte(r rune, enc Encoding) (byte, bool) {
+	if enc == EncodingWindows1252 {
+		for i, w := range windows1252 {
+			if w == r {
+				return byte(0x80 + i), true
+			}
+		}
+		if r >= 0x80 && r <= 0x9F {
+			return 0, false
+		}
+	}
+	if r > 0xFF {
+		return 0, false
+	}
+	return byte(r), true
+}
@@ -0,0 +1,109 @@
+package pgn_test
+
+import (
+	"testing"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestDecodeLatin1(t *testing.T) {
+	got := pgn.DecodeLatin1([]byte("Caf\xe9"))
+	if got != "Café" {
+		t.Fatalf("Got %q", got)
+	}
+}
+
+func TestDetectEncoding(t *testing.T) {
+	data := []struct {
+		name string
+		in   []byte
+		out  pgn.Encoding
+	}{
+		{name: "ASCII", in: []byte("[White \"Tal\"]"), out: pgn.EncodingUTF8},
+		{name: "UTF-8", in: []byte("[White \"Café\"]"), out: pgn.EncodingUTF8},
+		{name: "UTF-8 BOM", in: []byte("\xEF\xBB\xBF[White \"Tal\"]"), out: pgn.EncodingUTF8},
+		{name: "UTF-16LE BOM", in: []byte("\xFF\xFE[\x00"), out: pgn.EncodingUTF16LE},
+		{name: "UTF-16BE BOM", in: []byte("\xFE\xFF\x00["), out: pgn.EncodingUTF16BE},
+		{name: "Latin-1", in: []byte("[White \"Caf\xe9\"]"), out: pgn.EncodingLatin1},
+		{name: "Windows-1252", in: []byte("{\x93Caf\xe9\x94}"), out: pgn.EncodingWindows1252},
+	}
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			got := pgn.DetectEncoding(test.in)
+			if got != test.out {
+				t.Fatalf("Got %s, expected %s", got, test.out)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	data := []struct {
+		name string
+		in   []byte
+		enc  pgn.Encoding
+		out  string
+	}{
+		{name: "UTF-8 BOM", in: []byte("\xEF\xBB\xBFCafé"), enc: pgn.EncodingAuto, out: "Café"},
+		{name: "UTF-16LE", in: []byte("\xFF\xFEC\x00a\x00f\x00\xe9\x00"), enc: pgn.EncodingAuto, out: "Café"},
+		{name: "UTF-16BE", in: []byte("\xFE\xFF\x00C\x00a\x00f\x00\xe9"), enc: pgn.EncodingAuto, out: "Café"},
+		{name: "Windows-1252", in: []byte("\x93Caf\xe9\x94"), enc: pgn.EncodingAuto, out: "“Café”"},
+		{name: "Declared Latin-1", in: []byte("\x93"), enc: pgn.EncodingLatin1, out: "\u0093"},
+	}
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			got := pgn.Decode(test.in, test.enc)
+			if got != test.out {
+				t.Fatalf("Got %q, expected %q", got, test.out)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	data := []struct {
+		name string
+		in   string
+		enc  pgn.Encoding
+		out  []byte
+	}{
+		{name: "UTF-8", in: "Café", enc: pgn.EncodingUTF8, out: []byte("Café")},
+		{name: "UTF-16LE", in: "Café", enc: pgn.EncodingUTF16LE, out: []byte("\xFF\xFEC\x00a\x00f\x00\xe9\x00")},
+		{name: "UTF-16BE", in: "Café", enc: pgn.EncodingUTF16BE, out: []byte("\xFE\xFF\x00C\x00a\x00f\x00\xe9")},
+		{name: "Latin-1", in: "Café", enc: pgn.EncodingLatin1, out: []byte("Caf\xe9")},
+		{name: "Windows-1252", in: "“Café”", enc: pgn.EncodingWindows1252, out: []byte("\x93Caf\xe9\x94")},
+		{name: "Not Latin-1", in: "“Café”", enc: pgn.EncodingLatin1, out: nil},
+	}
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := pgn.Encode(test.in, test.enc)
+			if test.out == nil {
+				if err == nil {
+					t.Fatalf("Got %q, expected an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(test.out) {
+				t.Fatalf("Got %q, expected %q", got, test.out)
+			}
+			if back := pgn.Decode(got, pgn.EncodingAuto); back != test.in {
+				t.Fatalf("Decoded %q, expected %q", back, test.in)
+			}
+		})
+	}
+}
+
+func TestNewScannerEncoding(t *testing.T) {
+	s := pgn.NewScannerEncoding([]byte("[White \"J\xf6rg\"]"), pgn.EncodingAuto)
+	lexer := pgn.NewLexer(s)
+	err, tokens := lexer.Tokenize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens[1].Value != "Jörg" {
+		t.Fatalf("Got %q", tokens[1].Value)
+	}
+}
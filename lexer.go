@@ -1,12 +1,18 @@
 package pgn
 
 import (
-	"errors"
+	"strconv"
+	"unicode"
 )
 
 const (
 	literalCastleKingside  = "O-O"
 	literalCastleQueenside = "O-O-O"
+	literalWhiteWins       = "1-0"
+	literalBlackWins       = "0-1"
+	literalDraw            = "1/2-1/2"
+	literalUnknownResult   = "*"
+	literalEllipsis        = "..."
 )
 
 type Lexer struct {
@@ -51,23 +57,88 @@ const (
 	TokenPromotionIndicator
 	TokenPromotionPiece
 	TokenCapture
+	TokenComment
+	TokenWhiteWins
+	TokenBlackWins
+	TokenUnknownResult
 )
 
+var tokenTypeNames = map[TokenType]string{
+	TokenLeftBracket:        "TokenLeftBracket",
+	TokenTagNameChar:        "TokenTagNameChar",
+	TokenTagName:            "TokenTagName",
+	TokenEOF:                "TokenEOF",
+	TokenTagPairOpen:        "TokenTagPairOpen",
+	TokenTagPairClose:       "TokenTagPairClose",
+	TokenTagValue:           "TokenTagValue",
+	TokenDoubleQuote:        "TokenDoubleQuote",
+	TokenLetter:             "TokenLetter",
+	TokenDigit:              "TokenDigit",
+	TokenSpecialChar:        "TokenSpecialChar",
+	TokenWhitespace:         "TokenWhitespace",
+	TokenRightBracket:       "TokenRightBracket",
+	TokenUnderscore:         "TokenUnderscore",
+	TokenString:             "TokenString",
+	TokenMoveNumber:         "TokenMoveNumber",
+	TokenFile:               "TokenFile",
+	TokenRank:               "TokenRank",
+	TokenPiece:              "TokenPiece",
+	TokenCastleKingside:     "TokenCastleKingside",
+	TokenCastleQueenside:    "TokenCastleQueenside",
+	TokenDraw:               "TokenDraw",
+	TokenCheck:              "TokenCheck",
+	TokenCheckmate:          "TokenCheckmate",
+	TokenPromotionIndicator: "TokenPromotionIndicator",
+	TokenPromotionPiece:     "TokenPromotionPiece",
+	TokenCapture:            "TokenCapture",
+	TokenComment:            "TokenComment",
+	TokenWhiteWins:          "TokenWhiteWins",
+	TokenBlackWins:          "TokenBlackWins",
+	TokenUnknownResult:      "TokenUnknownResult",
+}
+
+// String returns the name of the token type, such as "TokenMoveNumber"
+func (t TokenType) String() string {
+	if name, ok := tokenTypeNames[t]; ok {
+		return name
+	}
+	return "TokenType(" + strconv.Itoa(int(t)) + ")"
+}
+
 const (
 	ERR_CASTLE             = "expected either queenside or kingside castle"
 	ERR_TAG_PAIR_CLOSE     = "Expected right square bracket but found none"
 	ERR_FILE               = "TokenFile expected to follow piece, but not found."
 	ERR_RANK               = "TokenRank expected to follow file, but not found."
 	ERR_STRING_START       = "Expected double quote to denote start of string token"
+	ERR_STRING_NOT_CLOSED  = "String not closed"
+	ERR_STRING_CHAR        = "Unexpected character in string"
 	ERR_DRAW               = "Expected game draw token"
 	ERR_PROMOTION          = "Expected promotion piece"
 	ERR_COMMENT_NOT_CLOSED = "Comment not closed"
+	ERR_NAG                = "Expected a NAG such as $1 or a suffix annotation such as !?"
 )
 
+// SyntaxError is an error in the PGN input, found at Pos. Its message is one
+// of the ERR_ constants.
+type SyntaxError struct {
+	Pos     Pos
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return e.Message
+}
+
+func (l *Lexer) syntaxError(message string) error {
+	return &SyntaxError{Pos: l.scanner.Pos(), Message: message}
+}
+
 type Token struct {
 	Value    string
 	Type     TokenType
 	Children []Token
+	Pos      Pos
 }
 
 /*
@@ -95,7 +166,7 @@ func (l *Lexer) Tokenize() (error, []Token) {
 }
 
 func (l *Lexer) tokenize(tokens []Token) (error, []Token) {
-	l.readWhitespace()
+	l.readWhitespaceAndEscapes()
 
 	startRune := l.scanner.Peek()
 
@@ -111,14 +182,17 @@ func (l *Lexer) tokenize(tokens []Token) (error, []Token) {
 
 		l.readWhitespace()
 
+		pos := l.scanner.Pos()
 		value := l.readTagName()
 		tokens = append(tokens, Token{
 			Value: value,
 			Type:  TokenTagName,
+			Pos:   pos,
 		})
 
 		l.readWhitespace()
 
+		pos = l.scanner.Pos()
 		err, value := l.readString()
 		if err != nil {
 			return err, tokens
@@ -126,22 +200,25 @@ func (l *Lexer) tokenize(tokens []Token) (error, []Token) {
 		tokens = append(tokens, Token{
 			Value: value,
 			Type:  TokenTagValue,
+			Pos:   pos,
 		})
 
 		l.readWhitespace()
 
 		if l.scanner.Peek() != ']' {
-			return errors.New(ERR_TAG_PAIR_CLOSE), tokens
+			return l.syntaxError(ERR_TAG_PAIR_CLOSE), tokens
 		} else {
 			l.scanner.Next()
 		}
 
-		if isNewLine(l.scanner.Peek()) {
-			l.scanner.Next()
+		// the rest of the line may hold more tag pairs, and any line break is
+		// consumed along with other whitespace by the next call
+		l.skip(isInlineWhiteSpace)
+		if !isNul(l.scanner.Peek()) {
 			return l.tokenize(tokens)
 		}
-	} else if isDigit(startRune) {
-		// Rule: movetext = move , {move} ;
+	} else {
+		// Rule: movetext = { element } , [ result ] ;
 		err, movetextTokens := l.readMovetext()
 		if err != nil {
 			return err, tokens
@@ -149,61 +226,115 @@ func (l *Lexer) tokenize(tokens []Token) (error, []Token) {
 		for _, t := range movetextTokens {
 			tokens = append(tokens, t)
 		}
-	} else if startRune == rune('\n') {
-		l.scanner.Next()
-		// start reading movetext
-		return l.tokenize(tokens)
+
+		// the next game, if there is one
+		l.readWhitespaceAndEscapes()
+		if !isNul(l.scanner.Peek()) {
+			return l.tokenize(tokens)
+		}
 	}
 
 	return nil, tokens
 }
 
-// Rule: movetext = move , {move} ;
-// Rule: move = move-number , piece , square ;
-// Rule: move-number = digit , {digit} , [.] ;
+// Rule: movetext = { element } , [ result ] ;
+// Rule: element = move-number | move | comment | nag | variation ;
+// Rule: move-number = digit , {digit} , {"."} ;
+// Rule: nag = "$" , digit , {digit} | "!" | "?" | "!!" | "??" | "!?" | "?!" ;
+// Rule: variation = "(" , { element } , ")" ;
+// readMovetext reads the movetext of a game up to and including its result,
+// or up to the next tag pair or the end of the input when it has none. A
+// variation is read as a TokenVariationOpen and a TokenVariationClose around
+// its elements. Suffix annotations such as !? are read as TokenNAG, as are
+// NAGs such as $1, and rest-of-line comments as TokenComment. Anything else
+// is a syntax error, so no part of the movetext is passed over.
 func (l *Lexer) readMovetext() (error, []Token) {
-	ok := true
 	tokens := []Token{}
-	for ok {
-		moveNumber := l.readMoveNumber()
-		if moveNumber != "" {
-			tokens = append(tokens, Token{
-				Type:  TokenMoveNumber,
-				Value: moveNumber,
-			})
-		} else {
-			ok = false
-		}
+	// opens holds where each variation not yet closed was opened
+	opens := []Pos{}
+	for {
+		l.readWhitespaceAndEscapes()
+
+		pos := l.scanner.Pos()
+		r := l.scanner.Peek()
+		switch {
+		case isNul(r) || isLBracket(r):
+			if len(opens) > 0 {
+				return &SyntaxError{Pos: opens[len(opens)-1], Message: ERR_VARIATION_NOT_CLOSED}, tokens
+			}
+			return nil, tokens
 
-		err, moveTokens := l.readMove()
-		if err != nil {
-			return err, tokens
-		}
-		for _, t := range moveTokens {
-			tokens = append(tokens, t)
-		}
+		case isCommentOpen(r):
+			err, _, token := l.readComment()
+			if err != nil {
+				return err, tokens
+			}
+			tokens = append(tokens, token)
 
-		l.readWhitespace()
+		case r == ';':
+			l.scanner.Next()
+			s := ""
+			for !isNul(l.scanner.Peek()) && !isNewLine(l.scanner.Peek()) {
+				s = s + string(l.scanner.Next())
+			}
+			tokens = append(tokens, Token{Type: TokenComment, Value: s, Pos: pos})
 
-		err, moveTokens = l.readMove()
-		if err != nil {
-			return err, tokens
-		}
-		for _, t := range moveTokens {
-			tokens = append(tokens, t)
-		}
+		case r == '$':
+			l.scanner.Next()
+			n := l.readInteger()
+			if n == "" {
+				return l.syntaxError(ERR_NAG), tokens
+			}
+			tokens = append(tokens, Token{Type: TokenNAG, Value: "$" + n, Pos: pos})
 
-		l.readWhitespace()
+		case r == '!' || r == '?':
+			s := ""
+			for l.scanner.Peek() == '!' || l.scanner.Peek() == '?' {
+				s = s + string(l.scanner.Next())
+			}
+			tokens = append(tokens, Token{Type: TokenNAG, Value: s, Pos: pos})
 
-		err = l.readComment()
-		if err != nil {
-			return err, tokens
+		case r == '(':
+			l.scanner.Next()
+			opens = append(opens, pos)
+			tokens = append(tokens, Token{Type: TokenVariationOpen, Value: "(", Pos: pos})
+
+		case r == ')':
+			if len(opens) == 0 {
+				return l.syntaxError(ERR_UNEXPECTED), tokens
+			}
+			l.scanner.Next()
+			opens = opens[:len(opens)-1]
+			tokens = append(tokens, Token{Type: TokenVariationClose, Value: ")", Pos: pos})
+
+		case isDigit(r) && !l.atResult():
+			tokens = append(tokens, Token{
+				Type:  TokenMoveNumber,
+				Value: l.readMoveNumber(),
+				Pos:   pos,
+			})
+
+		default:
+			err, moveTokens := l.readMove()
+			if err != nil {
+				return err, tokens
+			}
+			if len(moveTokens) == 0 {
+				return l.syntaxError(ERR_UNEXPECTED), tokens
+			}
+			for _, t := range moveTokens {
+				tokens = append(tokens, t)
+			}
+			if isResultToken(&moveTokens[0]) && len(opens) == 0 {
+				return nil, tokens
+			}
 		}
 	}
-	return nil, tokens
 }
 
 func (l *Lexer) readCastle() (error, bool, Token) {
+	pos := l.scanner.Pos()
+
 	r := l.scanner.Peek()
 	if r != rune('O') {
 		return nil, false, Token{}
@@ -212,13 +343,13 @@ func (l *Lexer) readCastle() (error, bool, Token) {
 
 	r = l.scanner.Peek()
 	if r != rune('-') {
-		return errors.New(ERR_CASTLE), false, Token{}
+		return l.syntaxError(ERR_CASTLE), false, Token{}
 	}
 	l.scanner.Next()
 
 	r = l.scanner.Peek()
 	if r != rune('O') {
-		return errors.New(ERR_CASTLE), false, Token{}
+		return l.syntaxError(ERR_CASTLE), false, Token{}
 	}
 	l.scanner.Next()
 
@@ -227,37 +358,55 @@ func (l *Lexer) readCastle() (error, bool, Token) {
 		return nil, true, Token{
 			Type:  TokenCastleKingside,
 			Value: literalCastleKingside,
+			Pos:   pos,
 		}
 	}
 	l.scanner.Next()
 
 	r = l.scanner.Next()
 	if r != 'O' {
-		return errors.New(ERR_CASTLE), false, Token{}
+		return l.syntaxError(ERR_CASTLE), false, Token{}
 	}
 
 	return nil, true, Token{
 		Type:  TokenCastleQueenside,
 		Value: literalCastleQueenside,
+		Pos:   pos,
 	}
 }
 
-func (l *Lexer) readDraw() (error, string) {
-	toMatch := "1/2-1/2"
-
-	r := l.scanner.Peek()
-	if r == rune(toMatch[0]) {
-		l.scanner.Next()
-		for _, rb := range toMatch[1:] {
-			r = l.scanner.Next()
-			if r != rb {
-				return errors.New(ERR_DRAW), ""
+// Rule: result = "1-0" | "0-1" | "1/2-1/2" | "*" ;
+func (l *Lexer) readResult() (error, bool, Token) {
+	pos := l.scanner.Pos()
+
+	for _, result := range []struct {
+		literal   string
+		tokenType TokenType
+	}{
+		{literalWhiteWins, TokenWhiteWins},
+		{literalBlackWins, TokenBlackWins},
+		{literalUnknownResult, TokenUnknownResult},
+	} {
+		if l.scanner.HasPrefix(result.literal) {
+			for range result.literal {
+				l.scanner.Next()
 			}
+			return nil, true, Token{Type: result.tokenType, Value: result.literal, Pos: pos}
+		}
+	}
+
+	if !l.scanner.HasPrefix("1/") {
+		// not a draw, and perhaps a move number such as 1... before
+		// Black's move
+		return nil, false, Token{}
+	}
+	for _, rb := range literalDraw {
+		r := l.scanner.Next()
+		if r != rb {
+			return l.syntaxError(ERR_DRAW), false, Token{}
 		}
-	} else {
-		return nil, ""
 	}
-	return nil, "1/2-1/2"
+	return nil, true, Token{Type: TokenDraw, Value: literalDraw, Pos: pos}
 }
 
 func (l *Lexer) readCheck() bool {
@@ -280,13 +429,15 @@ func (l *Lexer) readPromotion() (error, []Token) {
 	tokens := []Token{}
 
 	if isPromotionIndicator(l.scanner.Peek()) {
+		pos := l.scanner.Pos()
 		l.scanner.Next()
+		piecePos := l.scanner.Pos()
 		promoPieceRune := l.scanner.Next()
 		if !isPromotionPiece(promoPieceRune) {
-			return errors.New(ERR_PROMOTION), tokens
+			return l.syntaxError(ERR_PROMOTION), tokens
 		}
-		tokens = append(tokens, Token{Type: TokenPromotionIndicator, Value: "="})
-		tokens = append(tokens, Token{Type: TokenPromotionPiece, Value: string(promoPieceRune)})
+		tokens = append(tokens, Token{Type: TokenPromotionIndicator, Value: "=", Pos: pos})
+		tokens = append(tokens, Token{Type: TokenPromotionPiece, Value: string(promoPieceRune), Pos: piecePos})
 	}
 	return nil, tokens
 }
@@ -302,15 +453,12 @@ func (l *Lexer) readCapture() bool {
 func (l *Lexer) readMove() (error, []Token) {
 	tokens := []Token{}
 
-	err, draw := l.readDraw()
+	err, resultFound, resultToken := l.readResult()
 	if err != nil {
 		return err, tokens
 	}
-	if draw != "" {
-		tokens = append(tokens, Token{
-			Type:  TokenDraw,
-			Value: draw,
-		})
+	if resultFound {
+		tokens = append(tokens, resultToken)
 		return nil, tokens
 	}
 
@@ -320,95 +468,128 @@ func (l *Lexer) readMove() (error, []Token) {
 	}
 	if castleFound {
 		tokens = append(tokens, castleToken)
-		return nil, tokens
+		return nil, append(tokens, l.readCheckOrCheckmate()...)
 	}
 
 	// piece is optional, for example e4 indicates that a Pawn (P) moved
+	pos := l.scanner.Pos()
 	piece := l.readPiece()
 	if piece != "" {
 		tokens = append(tokens, Token{
 			Type:  TokenPiece,
 			Value: piece,
+			Pos:   pos,
 		})
 	}
 
-	// a file is optional before capture
-	// Example: 12. cxb5 axb5
-	// file := l.readFile()
-	// if file != "" {
-	// 	tokens = append(tokens, Token{
-	// 		Type:  TokenFile,
-	// 		Value: file,
-	// 	})
-	// }
+	// Rule: san = [piece] , [file] , [rank] , ["x"] , [file , rank] ;
+	// The square is read in two halves. When the second half is present the
+	// first is the disambiguation, the file and/or rank of the originating
+	// square, or the originating file of a capturing pawn.
+	// Examples: e4, exd5, Nbd7, R1e2, Qh4xe1, Nxe4
+	// A pawn move never starts with a rank, which would be the digit of the
+	// next move number.
+	fromFile, fromRank, fromTokens := l.readSquareParts(piece != "")
+	for _, t := range fromTokens {
+		tokens = append(tokens, t)
+	}
 
-	if l.readCapture() {
+	pos = l.scanner.Pos()
+	capture := l.readCapture()
+	if capture {
 		tokens = append(tokens, Token{
 			Type:  TokenCapture,
 			Value: "x",
+			Pos:   pos,
 		})
 	}
 
-	// TokenFile is required
-	file := l.readFile()
-	if file != "" {
-		tokens = append(tokens, Token{
-			Type:  TokenFile,
-			Value: file,
-		})
-	} else if piece != "" {
-		return errors.New(ERR_FILE), tokens
-	} else {
-		// no piece and no file found, so not a move
-		return nil, tokens
+	file, rank, toTokens := l.readSquareParts(false)
+	for _, t := range toTokens {
+		tokens = append(tokens, t)
 	}
 
-	// a second file is optional
-	// if it exists, the previous file is the disambiguation, indicating
-	// the originating file for the moving piece
-	file = l.readFile()
-	if file != "" {
-		tokens = append(tokens, Token{
-			Type:  TokenFile,
-			Value: file,
-		})
+	if file == "" && rank == "" && !capture {
+		// the first half was the square itself
+		file, rank = fromFile, fromRank
 	}
 
-	rank := l.readRank()
-	if rank != "" {
-		tokens = append(tokens, Token{
-			Type:  TokenRank,
-			Value: rank,
-		})
-	} else {
-		return errors.New(ERR_RANK), tokens
+	if file == "" {
+		if piece == "" && fromFile == "" && fromRank == "" && !capture {
+			// no piece and no square found, so not a move
+			return nil, tokens
+		}
+		return l.syntaxError(ERR_FILE), tokens
+	}
+	if rank == "" {
+		return l.syntaxError(ERR_RANK), tokens
 	}
 
+	err, promoTokens := l.readPromotion()
+	if err != nil {
+		return err, tokens
+	}
+	for _, t := range promoTokens {
+		tokens = append(tokens, t)
+	}
+
+	return nil, append(tokens, l.readCheckOrCheckmate()...)
+}
+
+func (l *Lexer) readCheckOrCheckmate() []Token {
+	tokens := []Token{}
+
+	pos := l.scanner.Pos()
 	if l.readCheck() {
 		tokens = append(tokens, Token{
 			Type:  TokenCheck,
 			Value: "+",
+			Pos:   pos,
 		})
 	}
 
+	pos = l.scanner.Pos()
 	if l.readCheckmate() {
 		tokens = append(tokens, Token{
 			Type:  TokenCheckmate,
 			Value: "#",
+			Pos:   pos,
 		})
 	}
 
-	err, promoTokens := l.readPromotion()
-	if err != nil {
-		return err, tokens
+	return tokens
+}
+
+// readSquareParts reads an optional file followed by an optional rank. A rank
+// without a file is only read when allowRank is true.
+func (l *Lexer) readSquareParts(allowRank bool) (string, string, []Token) {
+	tokens := []Token{}
+
+	pos := l.scanner.Pos()
+	file := l.readFile()
+	if file != "" {
+		tokens = append(tokens, Token{
+			Type:  TokenFile,
+			Value: file,
+			Pos:   pos,
+		})
 	}
-	if len(tokens) > 0 {
-		for _, t := range promoTokens {
-			tokens = append(tokens, t)
-		}
+
+	if file == "" && !allowRank {
+		return file, "", tokens
 	}
 
-	return nil, tokens
+	pos = l.scanner.Pos()
+	rank := l.readRank()
+	if rank != "" {
+		tokens = append(tokens, Token{
+			Type:  TokenRank,
+			Value: rank,
+			Pos:   pos,
+		})
+	}
+
+	return file, rank, tokens
 }
 
 func (l *Lexer) readFile() string {
@@ -429,15 +610,30 @@ func (l *Lexer) readRank() string {
 	return ""
 }
 
+// readMoveNumber reads a move number and the periods after it. The value is
+// the number alone, or the number followed by an ellipsis, as in 3... Nf6,
+// when more than one period marks the number of Black's move.
 func (l *Lexer) readMoveNumber() string {
 	s := ""
 
+	// a result such as 1-0 starts with a digit but is not a move number
+	if l.atResult() {
+		return s
+	}
+
 	moveNumber := l.readInteger()
 	if moveNumber != "" {
 		s = s + moveNumber
 	}
 
-	l.skip(isPeriod)
+	periods := 0
+	for isPeriod(l.scanner.Peek()) {
+		l.scanner.Next()
+		periods++
+	}
+	if periods > 1 {
+		s = s + literalEllipsis
+	}
 	l.skip(isWhiteSpace)
 
 	return s
@@ -497,6 +693,18 @@ func (l *Lexer) readTagName() string {
 	return s
 }
 
+// readWhitespaceAndEscapes reads whitespace and escape lines, which start
+// with % in the first column and run to the end of the line
+func (l *Lexer) readWhitespaceAndEscapes() {
+	for {
+		l.readWhitespace()
+		if l.scanner.Peek() != '%' || l.scanner.Pos().Column != 1 {
+			return
+		}
+		l.skip(func(r rune) bool { return !isNul(r) && !isNewLine(r) })
+	}
+}
+
 func (l *Lexer) readWhitespace() {
 	ok := true
 	for ok {
@@ -509,50 +717,74 @@ func (l *Lexer) readWhitespace() {
 	return
 }
 
-func (l *Lexer) readComment() error {
-	if isCommentOpen(l.scanner.Peek()) {
-		l.scanner.Next()
+// readComment reads a brace comment. The comment text may hold any UTF-8
+// text and runs up to the first closing brace; braces do not nest.
+func (l *Lexer) readComment() (error, bool, Token) {
+	if !isCommentOpen(l.scanner.Peek()) {
+		return nil, false, Token{}
+	}
+	pos := l.scanner.Pos()
+	l.scanner.Next()
 
-		for {
-			r := l.scanner.Next()
-			if isNul(r) {
-				return errors.New(ERR_COMMENT_NOT_CLOSED)
-			}
-			if isCommentClose(r) {
-				return nil
+	s := ""
+	for {
+		r := l.scanner.Next()
+		if isNul(r) {
+			// reported where the comment opens, as the end of the input
+			// says nothing about which brace was left open
+			return &SyntaxError{Pos: pos, Message: ERR_COMMENT_NOT_CLOSED}, false, Token{}
+		}
+		if isCommentClose(r) {
+			return nil, true, Token{
+				Type:  TokenComment,
+				Value: s,
+				Pos:   pos,
 			}
 		}
-
+		s = s + string(r)
 	}
-	return nil
 }
 
+// readString reads a double quoted string. A backslash escapes a double
+// quote or another backslash; any other backslash is kept as it is. A string
+// cut off by the end of the line or input is reported at its opening quote,
+// and a control character in it where it stands.
 func (l *Lexer) readString() (error, string) {
 	s := ""
-	ok := true
 
 	// check for opening dbl quote
+	pos := l.scanner.Pos()
 	peekValue := l.scanner.Peek()
 	if !isDoubleQuote(peekValue) {
-		return errors.New(ERR_STRING_START), s
+		return l.syntaxError(ERR_STRING_START), s
 	}
 	l.scanner.Next()
 
 	// don't collect the opening quote
 
-	for ok {
+	for {
 		peekVal := l.scanner.Peek()
 		if isDoubleQuote(peekVal) {
 			l.scanner.Next()
 			return nil, s
-		} else if isPrintingChar(peekVal) || isWhiteSpace(peekVal) {
+		} else if isBackslash(peekVal) {
+			l.scanner.Next()
+			escaped := l.scanner.Peek()
+			if isDoubleQuote(escaped) || isBackslash(escaped) {
+				l.scanner.Next()
+				s = s + string(escaped)
+			} else {
+				s = s + string(peekVal)
+			}
+		} else if isPrintingChar(peekVal) || isInlineWhiteSpace(peekVal) {
 			nextVal := l.scanner.Next()
 			s = s + string(nextVal)
+		} else if isNul(peekVal) || isNewLine(peekVal) {
+			return &SyntaxError{Pos: pos, Message: ERR_STRING_NOT_CLOSED}, s
 		} else {
-			ok = false
+			return l.syntaxError(ERR_STRING_CHAR), s
 		}
 	}
-	return nil, s
 }
 
 func isLBracket(r rune) bool     { return r == rune('[') }
@@ -560,8 +792,12 @@ func isRBracket(r rune) bool     { return r == rune(']') }
 func isCommentOpen(r rune) bool  { return r == rune('{') }
 func isCommentClose(r rune) bool { return r == rune('}') }
 func isDoubleQuote(r rune) bool  { return r == rune('"') }
-func isWhiteSpace(r rune) bool   { return r == rune(' ') }
-func isNewLine(r rune) bool      { return r == rune('\n') }
+func isBackslash(r rune) bool    { return r == rune('\\') }
+func isNewLine(r rune) bool      { return r == rune('\n') || r == rune('\r') }
+func isWhiteSpace(r rune) bool   { return isInlineWhiteSpace(r) || isNewLine(r) }
+func isInlineWhiteSpace(r rune) bool {
+	return r == rune(' ') || r == rune('\t') || r == rune('\v') || r == rune('\f')
+}
 func isLetter(r rune) bool {
 	return (r >= 65 && r <= 90) || (r >= 97 && r <= 122)
 }
@@ -588,6 +824,12 @@ func isSpecialChar(r rune) bool {
 		(r >= 123 && r <= 126)
 }
 
+// isPrintingChar accepts the ASCII printing characters and, beyond ASCII, any
+// graphic Unicode character, spaces such as the no-break space included, so
+// that names such as "Café" survive
 func isPrintingChar(r rune) bool {
+	if r > unicode.MaxASCII {
+		return unicode.IsGraphic(r)
+	}
 	return isLetter(r) || isDigit(r) || isSpecialChar(r)
 }
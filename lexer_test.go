@@ -48,6 +48,46 @@ func TestTokenize(t *testing.T) {
 				pgn.Token{Type: pgn.TokenTagValue, Value: "F/S      Return      Match"},
 			},
 		},
+		{
+			name: "Tag Pair - Escaped Quote and Backslash",
+			in:   "[Event \"The \\\"Immortal\\\" \\\\ Game\"]",
+			out: []pgn.Token{
+				pgn.Token{Type: pgn.TokenTagName, Value: "Event"},
+				pgn.Token{Type: pgn.TokenTagValue, Value: "The \"Immortal\" \\ Game"},
+			},
+		},
+		{
+			name: "Tag Pair - Non-ASCII",
+			in:   "[White \"Café, Ян\"]",
+			out: []pgn.Token{
+				pgn.Token{Type: pgn.TokenTagName, Value: "White"},
+				pgn.Token{Type: pgn.TokenTagValue, Value: "Café, Ян"},
+			},
+		},
+		{
+			name: "Tag Pair - No-Break Space",
+			in:   "[White \"A\u00a0B\"]",
+			out: []pgn.Token{
+				pgn.Token{Type: pgn.TokenTagName, Value: "White"},
+				pgn.Token{Type: pgn.TokenTagValue, Value: "A\u00a0B"},
+			},
+		},
+		{
+			name: "Tag Pair - String Not Closed",
+			in:   "[White \"Fischer]\n",
+			out: []pgn.Token{
+				pgn.Token{Type: pgn.TokenTagName, Value: "White"},
+			},
+			errorMessage: pgn.ERR_STRING_NOT_CLOSED,
+		},
+		{
+			name: "Tag Pair - Control Character",
+			in:   "[White \"Fi\x01scher\"]",
+			out: []pgn.Token{
+				pgn.Token{Type: pgn.TokenTagName, Value: "White"},
+			},
+			errorMessage: pgn.ERR_STRING_CHAR,
+		},
 		{
 			name: "Multiple Tag Pairs",
 			in: "[Event \"F/S Return Match\"]\n" +
@@ -98,6 +138,47 @@ func TestTokenize(t *testing.T) {
 				newMove("2", "N", "f3", "N", "c6"),
 			),
 		},
+		{
+			name: "Movetext - NAGs, Variations and Rest-of-line Comments",
+			in:   "1. e4 $1 (1. d4!?) e5 ; solid\n1-0",
+			out: []pgn.Token{
+				pgn.Token{Type: pgn.TokenMoveNumber, Value: "1"},
+				pgn.Token{Type: pgn.TokenFile, Value: "e"},
+				pgn.Token{Type: pgn.TokenRank, Value: "4"},
+				pgn.Token{Type: pgn.TokenNAG, Value: "$1"},
+				pgn.Token{Type: pgn.TokenVariationOpen, Value: "("},
+				pgn.Token{Type: pgn.TokenMoveNumber, Value: "1"},
+				pgn.Token{Type: pgn.TokenFile, Value: "d"},
+				pgn.Token{Type: pgn.TokenRank, Value: "4"},
+				pgn.Token{Type: pgn.TokenNAG, Value: "!?"},
+				pgn.Token{Type: pgn.TokenVariationClose, Value: ")"},
+				pgn.Token{Type: pgn.TokenFile, Value: "e"},
+				pgn.Token{Type: pgn.TokenRank, Value: "5"},
+				pgn.Token{Type: pgn.TokenComment, Value: " solid"},
+				pgn.Token{Type: pgn.TokenWhiteWins, Value: "1-0"},
+			},
+		},
+		{
+			name: "Movetext - Move Number Before Black's Move",
+			in:   "1... e5",
+			out: []pgn.Token{
+				pgn.Token{Type: pgn.TokenMoveNumber, Value: "1..."},
+				pgn.Token{Type: pgn.TokenFile, Value: "e"},
+				pgn.Token{Type: pgn.TokenRank, Value: "5"},
+			},
+		},
+		{
+			name:         "Movetext - Unexpected Character",
+			in:           "1. e4 @ e5",
+			out:          []pgn.Token{},
+			errorMessage: pgn.ERR_UNEXPECTED,
+		},
+		{
+			name:         "Movetext - Variation Not Closed",
+			in:           "1. e4 (1. d4",
+			out:          []pgn.Token{},
+			errorMessage: pgn.ERR_VARIATION_NOT_CLOSED,
+		},
 		{
 			name:         "Movetext - Castle Invalid",
 			in:           "1. O-",
@@ -204,6 +285,24 @@ func TestTokenize(t *testing.T) {
 				},
 			),
 		},
+		{
+			name: "Movetext - Results",
+			in:   "1. e4 1-0 2. d4 0-1 3. c4 *",
+			out: []pgn.Token{
+				pgn.Token{Type: pgn.TokenMoveNumber, Value: "1"},
+				pgn.Token{Type: pgn.TokenFile, Value: "e"},
+				pgn.Token{Type: pgn.TokenRank, Value: "4"},
+				pgn.Token{Type: pgn.TokenWhiteWins, Value: "1-0"},
+				pgn.Token{Type: pgn.TokenMoveNumber, Value: "2"},
+				pgn.Token{Type: pgn.TokenFile, Value: "d"},
+				pgn.Token{Type: pgn.TokenRank, Value: "4"},
+				pgn.Token{Type: pgn.TokenBlackWins, Value: "0-1"},
+				pgn.Token{Type: pgn.TokenMoveNumber, Value: "3"},
+				pgn.Token{Type: pgn.TokenFile, Value: "c"},
+				pgn.Token{Type: pgn.TokenRank, Value: "4"},
+				pgn.Token{Type: pgn.TokenUnknownResult, Value: "*"},
+			},
+		},
 		{
 			name: "Movetext - Checking Move - White",
 			in:   "43. Ke6+ Bf4",
@@ -330,6 +429,19 @@ func TestTokenize(t *testing.T) {
 				},
 			),
 		},
+		{
+			name: "Comments",
+			in:   "1. e4 {Königsbauer} e5 {Ruy [%clk 0:03:00]}",
+			out: []pgn.Token{
+				pgn.Token{Type: pgn.TokenMoveNumber, Value: "1"},
+				pgn.Token{Type: pgn.TokenFile, Value: "e"},
+				pgn.Token{Type: pgn.TokenRank, Value: "4"},
+				pgn.Token{Type: pgn.TokenComment, Value: "Königsbauer"},
+				pgn.Token{Type: pgn.TokenFile, Value: "e"},
+				pgn.Token{Type: pgn.TokenRank, Value: "5"},
+				pgn.Token{Type: pgn.TokenComment, Value: "Ruy [%clk 0:03:00]"},
+			},
+		},
 		{
 			name:         "Comment without closing brace",
 			in:           "1. a4 e5 { aslks  klasdf i23lk43nncklj3#$1412kfdlsjf ",
@@ -377,6 +489,26 @@ func TestTokenize(t *testing.T) {
 				pgn.Token{Type: pgn.TokenRank, Value: "5"},
 			},
 		},
+		{
+			name: "Tabs, CR LF and form feeds",
+			in:   "[Event \"Blah Blah\"]\r\n\r\n1.\te4\te5\r\n\t2. Nf3\fNc6\r\n",
+			out: []pgn.Token{
+				pgn.Token{Type: pgn.TokenTagName, Value: "Event"},
+				pgn.Token{Type: pgn.TokenTagValue, Value: "Blah Blah"},
+				pgn.Token{Type: pgn.TokenMoveNumber, Value: "1"},
+				pgn.Token{Type: pgn.TokenFile, Value: "e"},
+				pgn.Token{Type: pgn.TokenRank, Value: "4"},
+				pgn.Token{Type: pgn.TokenFile, Value: "e"},
+				pgn.Token{Type: pgn.TokenRank, Value: "5"},
+				pgn.Token{Type: pgn.TokenMoveNumber, Value: "2"},
+				pgn.Token{Type: pgn.TokenPiece, Value: "N"},
+				pgn.Token{Type: pgn.TokenFile, Value: "f"},
+				pgn.Token{Type: pgn.TokenRank, Value: "3"},
+				pgn.Token{Type: pgn.TokenPiece, Value: "N"},
+				pgn.Token{Type: pgn.TokenFile, Value: "c"},
+				pgn.Token{Type: pgn.TokenRank, Value: "6"},
+			},
+		},
 		{
 			name: "movetext with capture",
 			in:   "12. cxb5 axb5",
@@ -647,3 +779,50 @@ func buildTokens(moveTokens ...[]pgn.Token) []pgn.Token {
 	}
 	return tokens
 }
+
+func TestTokenizeErrorPos(t *testing.T) {
+	data := []struct {
+		in  string
+		pos pgn.Pos
+	}{
+		{"1. e4 {never closed\ne5 2. Nf3\n", pgn.Pos{Offset: 6, Line: 1, Column: 7}},
+		{"1. e4 (1. d4 d5\n(1... Nf6) 2. c4\n", pgn.Pos{Offset: 6, Line: 1, Column: 7}},
+		{"1. e4 e5 2. Nf3 @", pgn.Pos{Offset: 16, Line: 1, Column: 17}},
+		{"[White \"Fischer]\n", pgn.Pos{Offset: 7, Line: 1, Column: 8}},
+		{"[White \"Fi\x01scher\"]", pgn.Pos{Offset: 10, Line: 1, Column: 11}},
+	}
+	for _, test := range data {
+		t.Run(test.in, func(t *testing.T) {
+			lexer := pgn.NewLexer(pgn.NewScanner(test.in))
+			err, _ := lexer.Tokenize()
+			syntaxErr, ok := err.(*pgn.SyntaxError)
+			if !ok {
+				t.Fatalf("Expected a syntax error, got %v", err)
+			}
+			if syntaxErr.Pos != test.pos {
+				fmt.Println("Got:", syntaxErr.Pos)
+				fmt.Println("Exp:", test.pos)
+				t.Fatal("Unexpected error position")
+			}
+		})
+	}
+}
+
+func TestTokenTypeString(t *testing.T) {
+	data := []struct {
+		in  pgn.TokenType
+		out string
+	}{
+		{pgn.TokenLeftBracket, "TokenLeftBracket"},
+		{pgn.TokenMoveNumber, "TokenMoveNumber"},
+		{pgn.TokenUnknownResult, "TokenUnknownResult"},
+		{pgn.TokenType(-1), "TokenType(-1)"},
+	}
+	for _, test := range data {
+		if got := test.in.String(); got != test.out {
+			fmt.Println("Got:", got)
+			fmt.Println("Exp:", test.out)
+			t.Fatal("Unexpected token type name")
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package pgn
+
+// ValidateMate replays the game and checks the check and checkmate markers
+// of each move, and the result, against the positions the moves lead to. A
+// move marked # that does not mate, a move marked + that does not check, and
+// a result that contradicts a final checkmate or stalemate are errors. A
+// check or mate the notation leaves unmarked is a warning, as is an unknown
+// result for a game that has ended on the board. Illegal moves are left to
+// ValidateMoves.
+func ValidateMate(g *Game) []Issue {
+	issues := []Issue{}
+	err := g.Replay(func(ply Ply) error {
+		issues = append(issues, validateMarkers(ply)...)
+		return nil
+	})
+	if err != nil {
+		return issues
+	}
+	final, err := g.FinalPosition()
+	if err != nil {
+		return issues
+	}
+
+	ending, expected := "", ResultDraw
+	switch {
+	case final.IsCheckmate():
+		ending, expected = "checkmate", ResultWhiteWins
+		if final.Turn == White {
+			expected = ResultBlackWins
+		}
+	case final.IsStalemate():
+		ending = "stalemate"
+	}
+	result := g.result()
+	if ending == "" || result == expected {
+		return issues
+	}
+
+	issue := Issue{
+		Severity: SeverityError,
+		Tag:      TagResult,
+		Message:  "result " + string(result) + " but the game ends in " + ending + ", " + string(expected),
+		Pos:      g.tagPos(TagResult),
+	}
+	switch result {
+	case "":
+		issue.Severity = SeverityWarning
+		issue.Message = "no result but the game ends in " + ending + ", " + string(expected)
+	case ResultUnknown:
+		issue.Severity = SeverityWarning
+	}
+	return append(issues, issue)
+}
+
+// validateMarkers checks the check and checkmate markers of a replayed move
+func validateMarkers(ply Ply) []Issue {
+	m, p := ply.Move, &ply.Position
+	mate, check := p.IsCheckmate(), p.InCheck()
+	issue := func(severity Severity, message string) []Issue {
+		return []Issue{{Severity: severity, Message: ply.Numbered() + " " + message, Pos: m.Pos}}
+	}
+	switch {
+	case m.Checkmate && !mate:
+		if check {
+			return issue(SeverityError, "is marked as checkmate but only gives check")
+		}
+		return issue(SeverityError, "is marked as checkmate but does not mate")
+	case m.Check && !check:
+		return issue(SeverityError, "is marked as check but does not give check")
+	case mate && !m.Checkmate:
+		return issue(SeverityWarning, "mates but is not marked #")
+	case check && !m.Check && !m.Checkmate:
+		return issue(SeverityWarning, "gives check but is not marked +")
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package pgn_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestIsCheckmate(t *testing.T) {
+	data := []struct {
+		fen       string
+		checkmate bool
+		stalemate bool
+	}{
+		{pgn.StartingFEN, false, false},
+		{"rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3", true, false},
+		{"7k/8/5KQ1/8/8/8/8/8 b - - 0 1", false, true},
+		{"7k/8/5K2/6Q1/8/8/8/8 b - - 0 1", false, false},
+	}
+	for _, test := range data {
+		t.Run(test.fen, func(t *testing.T) {
+			p, err := pgn.ParseFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.IsCheckmate(); got != test.checkmate {
+				fmt.Println("Got:", got)
+				fmt.Println("Exp:", test.checkmate)
+				t.Fatal("Unexpected checkmate")
+			}
+			if got := p.IsStalemate(); got != test.stalemate {
+				fmt.Println("Got:", got)
+				fmt.Println("Exp:", test.stalemate)
+				t.Fatal("Unexpected stalemate")
+			}
+		})
+	}
+}
+
+func TestValidateMate(t *testing.T) {
+	stalemate := "[SetUp \"1\"]\n[FEN \"7k/8/5K2/6Q1/8/8/8/8 w - - 0 1\"]\n\n"
+	data := []struct {
+		name string
+		in   string
+		exp  []string
+	}{
+		{"Checkmate", "1. f3 e5 2. g4 Qh4# 0-1", []string{}},
+		{"Resignation", "1. e4 e5 2. Qh5 Nc6 1-0", []string{}},
+		{"False checkmate", "1. e4 f5 2. Qh5# 1-0", []string{
+			"error: 2. Qh5# is marked as checkmate but only gives check",
+		}},
+		{"False check", "1. e4+ e5 *", []string{
+			"error: 1. e4+ is marked as check but does not give check",
+		}},
+		{"Unmarked check", "1. e4 f5 2. Qh5 g6 *", []string{
+			"warning: 2. Qh5 gives check but is not marked +",
+		}},
+		{"Unmarked checkmate", "1. f3 e5 2. g4 Qh4 0-1", []string{
+			"warning: 2... Qh4 mates but is not marked #",
+		}},
+		{"Wrong winner", "1. f3 e5 2. g4 Qh4# 1-0", []string{
+			"error: Result: result 1-0 but the game ends in checkmate, 0-1",
+		}},
+		{"Unknown result", "1. f3 e5 2. g4 Qh4# *", []string{
+			"warning: Result: result * but the game ends in checkmate, 0-1",
+		}},
+		{"Stalemate", stalemate + "1. Qg6 1/2-1/2", []string{}},
+		{"Won stalemate", stalemate + "1. Qg6 1-0", []string{
+			"error: Result: result 1-0 but the game ends in stalemate, 1/2-1/2",
+		}},
+	}
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			var unmarshalled pgn.PGN
+			if err := pgn.Unmarshal(test.in, &unmarshalled); err != nil {
+				t.Fatal(err)
+			}
+			got := []string{}
+			for _, issue := range pgn.ValidateMate(&unmarshalled.Games[0]) {
+				got = append(got, issue.String())
+			}
+			if strings.Join(got, "\n") != strings.Join(test.exp, "\n") {
+				fmt.Println("Got:", got)
+				fmt.Println("Exp:", test.exp)
+				t.Fatal("Unexpected issues")
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package pgn
+
+import (
+	"errors"
+	"strings"
+)
+
+const ERR_MATERIAL = "Expected material such as KQ v KRR"
+
+// Pieces counts the pieces of one side, kings left out
+type Pieces struct {
+	Pawns   int
+	Knights int
+	Bishops int
+	Rooks   int
+	Queens  int
+}
+
+// Value returns the material value of the pieces, counting a pawn as 1, a
+// knight or bishop as 3, a rook as 5 and a queen as 9
+func (ps Pieces) Value() int {
+	return ps.Pawns + 3*ps.Knights + 3*ps.Bishops + 5*ps.Rooks + 9*ps.Queens
+}
+
+// String writes the pieces as a material signature with the king first, such
+// as KRPP
+func (ps Pieces) String() string {
+	return "K" + strings.Repeat("Q", ps.Queens) + strings.Repeat("R", ps.Rooks) +
+		strings.Repeat("B", ps.Bishops) + strings.Repeat("N", ps.Knights) + strings.Repeat("P", ps.Pawns)
+}
+
+// Material is the material of both sides
+type Material struct {
+	White Pieces
+	Black Pieces
+}
+
+// Balance returns the value of White's material less the value of Black's
+func (m Material) Balance() int {
+	return m.White.Value() - m.Black.Value()
+}
+
+// Imbalanced reports whether the sides have different pieces, pawns aside,
+// such as a rook against a bishop and knight, whatever their values
+func (m Material) Imbalanced() bool {
+	w, b := m.White, m.Black
+	w.Pawns, b.Pawns = 0, 0
+	return w != b
+}
+
+// String writes the material as White's signature against Black's, such as
+// KRP v KR
+func (m Material) String() string {
+	return m.White.String() + " v " + m.Black.String()
+}
+
+// Material counts the pieces of each side
+func (p *Position) Material() Material {
+	m := Material{}
+	for _, c := range p.board {
+		ps := &m.White
+		if pieceColor(c) == Black {
+			ps = &m.Black
+		}
+		switch upper(c) {
+		case 'P':
+			ps.Pawns++
+		case 'N':
+			ps.Knights++
+		case 'B':
+			ps.Bishops++
+		case 'R':
+			ps.Rooks++
+		case 'Q':
+			ps.Queens++
+		}
+	}
+	return m
+}
+
+// ParseMaterial parses a material signature of two sides separated by v or
+// vs, such as "KQ v KRR", "Q vs RR" or "KRPvKR". Kings may be left out.
+func ParseMaterial(s string) (Material, error) {
+	sides := strings.Split(strings.Replace(s, "vs", "v", 1), "v")
+	if len(sides) != 2 {
+		return Material{}, errors.New(ERR_MATERIAL)
+	}
+	white, ok := parsePieces(strings.TrimSpace(sides[0]))
+	if !ok {
+		return Material{}, errors.New(ERR_MATERIAL)
+	}
+	black, ok := parsePieces(strings.TrimSpace(sides[1]))
+	if !ok {
+		return Material{}, errors.New(ERR_MATERIAL)
+	}
+	return Material{White: white, Black: black}, nil
+}
+
+func parsePieces(s string) (Pieces, bool) {
+	ps := Pieces{}
+	for i, c := range strings.ToUpper(s) {
+		switch c {
+		case 'K':
+			if i != 0 {
+				return ps, false
+			}
+		case 'P':
+			ps.Pawns++
+		case 'N':
+			ps.Knights++
+		case 'B':
+			ps.Bishops++
+		case 'R':
+			ps.Rooks++
+		case 'Q':
+			ps.Queens++
+		default:
+			return ps, false
+		}
+	}
+	return ps, true
+}
+
+// MaterialIs returns a match function for FindPositions that finds positions
+// with the given material, held by either side. Pawns are only compared when
+// the material has some: "Q v RR" finds a queen against two rooks with any
+// pawns, while "KQP v KRR" needs exactly one pawn on the queen's side and
+// none on the other.
+func MaterialIs(m Material) func(*Position) bool {
+	swapped := Material{White: m.Black, Black: m.White}
+	pawns := m.White.Pawns > 0 || m.Black.Pawns > 0
+	return func(p *Position) bool {
+		got := p.Material()
+		if !pawns {
+			got.White.Pawns, got.Black.Pawns = 0, 0
+		}
+		return got == m || got == swapped
+	}
+}
+
+// PiecesAre returns a match function for FindPositions that finds positions
+// where both sides have the given pieces, pawns aside, and any number of pawns
+func PiecesAre(ps Pieces) func(*Position) bool {
+	ps.Pawns = 0
+	return func(p *Position) bool {
+		m := p.Material()
+		m.White.Pawns, m.Black.Pawns = 0, 0
+		return m.White == ps && m.Black == ps
+	}
+}
+
+// IsRookEnding reports whether each side has a single rook and no other
+// pieces besides pawns
+func IsRookEnding(p *Position) bool {
+	return PiecesAre(Pieces{Rooks: 1})(p)
+}
+
+// PawnsOnOneWing reports whether there are pawns and all of them stand on
+// the queenside, files a to d, or all on the kingside, files e to h
+func PawnsOnOneWing(p *Position) bool {
+	queenside, kingside := false, false
+	for i, c := range p.board {
+		if upper(c) != 'P' {
+			continue
+		}
+		if fileOf(i) < 4 {
+			queenside = true
+		} else {
+			kingside = true
+		}
+	}
+	return queenside != kingside
+}
+
+// OppositeColoredBishops reports whether each side has a single bishop and
+// the two bishops move on squares of different colours
+func OppositeColoredBishops(p *Position) bool {
+	white, black := -1, -1
+	for i, c := range p.board {
+		switch c {
+		case 'B':
+			if white >= 0 {
+				return false
+			}
+			white = i
+		case 'b':
+			if black >= 0 {
+				return false
+			}
+			black = i
+		}
+	}
+	if white < 0 || black < 0 {
+		return false
+	}
+	return (fileOf(white)+rankOf(white))%2 != (fileOf(black)+rankOf(black))%2
+}
+
+// IsImbalanced reports whether the sides have different pieces, pawns aside
+func IsImbalanced(p *Position) bool {
+	return p.Material().Imbalanced()
+}
+
+// All returns a match function that matches positions matched by all of fns
+func All(fns ...func(*Position) bool) func(*Position) bool {
+	return func(p *Position) bool {
+		for _, fn := range fns {
+			if !fn(p) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// PlyRange is a run of consecutive plies of a game, from First to Last
+type PlyRange struct {
+	First Ply
+	Last  Ply
+}
+
+// Len returns the number of plies in the range
+func (r PlyRange) Len() int {
+	return r.Last.Index - r.First.Index + 1
+}
+
+// FindRuns replays the game and returns every run of at least minPlies
+// consecutive plies after which the position matches, such as a material
+// imbalance that lasts rather than one in the middle of an exchange
+func (g *Game) FindRuns(match func(p *Position) bool, minPlies int) []PlyRange {
+	runs := []PlyRange{}
+	var run *PlyRange
+	end := func() {
+		if run != nil && run.Len() >= minPlies {
+			runs = append(runs, *run)
+		}
+		run = nil
+	}
+	for _, ply := range g.FindPositions(match) {
+		if run != nil && ply.Index == run.Last.Index+1 {
+			run.Last = ply
+			continue
+		}
+		end()
+		run = &PlyRange{First: ply, Last: ply}
+	}
+	end()
+	return runs
+}
@@ -0,0 +1,93 @@
+package pgn_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestMaterial(t *testing.T) {
+	p, err := pgn.ParseFEN("6k1/5ppp/8/8/8/8/1Q3PPP/6K1 w - - 0 1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := p.Material()
+	if m.String() != "KQPPP v KPPP" || m.Balance() != 9 || !m.Imbalanced() {
+		fmt.Println("Got:", m, m.Balance(), m.Imbalanced())
+		t.Fatal("Unexpected material")
+	}
+
+	for _, in := range []string{"KQ v KRR", "Q vs RR", "KQvKRR"} {
+		got, err := pgn.ParseMaterial(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.String() != "KQ v KRR" {
+			fmt.Println("Got:", got)
+			t.Fatalf("Unexpected material for %q", in)
+		}
+	}
+	for _, in := range []string{"KQ", "QK v R", "KX v K", "K v K v K"} {
+		if _, err := pgn.ParseMaterial(in); err == nil || err.Error() != pgn.ERR_MATERIAL {
+			fmt.Println("Got:", err)
+			t.Fatalf("Expected an error for %q", in)
+		}
+	}
+}
+
+func TestPositionPredicates(t *testing.T) {
+	data := []struct {
+		name  string
+		match func(*pgn.Position) bool
+		fen   string
+		exp   bool
+	}{
+		{"Rook ending", pgn.IsRookEnding, "6k1/5ppp/8/8/8/8/r4PPP/R5K1 w - - 0 1", true},
+		{"Not a rook ending", pgn.IsRookEnding, "6k1/5ppp/8/8/8/8/r4PPP/RB4K1 w - - 0 1", false},
+		{"Pawns on one wing", pgn.PawnsOnOneWing, "6k1/5ppp/8/8/8/8/r4PPP/R5K1 w - - 0 1", true},
+		{"Pawns on both wings", pgn.PawnsOnOneWing, "6k1/p4ppp/8/8/8/8/r4PPP/R5K1 w - - 0 1", false},
+		{"Opposite bishops", pgn.OppositeColoredBishops, "6k1/5ppp/4b3/8/8/8/5PPP/2B3K1 w - - 0 1", true},
+		{"Same bishops", pgn.OppositeColoredBishops, "6k1/5ppp/3b4/8/8/8/5PPP/2B3K1 w - - 0 1", false},
+		{"Queen v two rooks", pgn.MaterialIs(pgn.Material{White: pgn.Pieces{Rooks: 2}, Black: pgn.Pieces{Queens: 1}}),
+			"3q2k1/8/8/8/8/8/8/R3R1K1 w - - 0 1", true},
+		{"Queen v two rooks with pawns", pgn.MaterialIs(pgn.Material{White: pgn.Pieces{Rooks: 2}, Black: pgn.Pieces{Queens: 1}}),
+			"3q2k1/5ppp/8/8/8/8/6PP/R3R1K1 w - - 0 1", true},
+		{"Queen and pawn v two rooks", pgn.MaterialIs(pgn.Material{White: pgn.Pieces{Rooks: 2}, Black: pgn.Pieces{Queens: 1, Pawns: 1}}),
+			"3q2k1/5ppp/8/8/8/8/6PP/R3R1K1 w - - 0 1", false},
+		{"Imbalance", pgn.IsImbalanced, "6k1/5ppp/8/8/8/8/b4PPP/R5K1 w - - 0 1", true},
+		{"All", pgn.All(pgn.IsRookEnding, pgn.PawnsOnOneWing), "6k1/p4ppp/8/8/8/8/r4PPP/R5K1 w - - 0 1", false},
+	}
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			p, err := pgn.ParseFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := test.match(&p); got != test.exp {
+				fmt.Println("Got:", got)
+				t.Fatal("Unexpected match")
+			}
+		})
+	}
+}
+
+func TestFindRuns(t *testing.T) {
+	// the knights are traded on d4, leaving the material unbalanced for one
+	// ply only
+	in := "1. e4 e5 2. Nf3 Nc6 3. Nc3 Nf6 4. d4 exd4 5. Nxd4 Nxd4 6. Qxd4 Bc5 *"
+	var unmarshalled pgn.PGN
+	if err := pgn.Unmarshal(in, &unmarshalled); err != nil {
+		t.Fatal(err)
+	}
+	runs := unmarshalled.Games[0].FindRuns(pgn.IsImbalanced, 2)
+	if len(runs) != 0 {
+		fmt.Println("Got:", runs)
+		t.Fatal("Expected no lasting imbalance")
+	}
+	runs = unmarshalled.Games[0].FindRuns(pgn.IsImbalanced, 1)
+	if len(runs) != 1 || runs[0].First.Index != 10 || runs[0].Len() != 1 {
+		fmt.Println("Got:", runs)
+		t.Fatal("Expected a single ply of imbalance")
+	}
+}
@@ -0,0 +1,91 @@
+package pgn
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Identity decides when two games are duplicates of each other
+type Identity int
+
+const (
+	// IdentityMovetext treats games as duplicates when their movetext,
+	// comments and termination marker included, is the same
+	IdentityMovetext Identity = iota
+	// IdentityGame treats games as duplicates when they have the same
+	// players, date and result, and the same moves
+	IdentityGame
+	// IdentityFinalPosition treats games as duplicates when their final
+	// positions have the same Key: the same pieces, side to move, castling
+	// rights and en passant capture. Games whose moves cannot be replayed are
+	// compared by movetext instead.
+	IdentityFinalPosition
+)
+
+func (i Identity) String() string {
+	switch i {
+	case IdentityMovetext:
+		return "movetext"
+	case IdentityGame:
+		return "game"
+	case IdentityFinalPosition:
+		return "position"
+	}
+	return ""
+}
+
+// Key returns the key by which the identity compares games: games with the
+// same key are duplicates
+func (i Identity) Key(g *Game) string {
+	switch i {
+	case IdentityGame:
+		return strings.Join([]string{
+			g.White(), g.Black(), g.Date(), g.Result(), g.moves(),
+		}, "\x00")
+	case IdentityFinalPosition:
+		if p, err := g.FinalPosition(); err == nil {
+			k := p.Key()
+			return fmt.Sprintf("position\x00%s\x00%d\x00%d\x00%d", k.board[:], k.castling, k.enPassant, k.turn)
+		}
+	}
+	return "movetext\x00" + strings.Join(g.movetextElements(), " ")
+}
+
+// Deduplicate returns the games with every game that duplicates an earlier
+// one removed
+func Deduplicate(games []Game, identity Identity) []Game {
+	seen := map[string]bool{}
+	unique := []Game{}
+	for i := range games {
+		key := identity.Key(&games[i])
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, games[i])
+	}
+	return unique
+}
+
+// Merge combines the games of several PGN databases, in order, removing
+// duplicates
+func Merge(identity Identity, in ...PGN) PGN {
+	games := []Game{}
+	for _, p := range in {
+		games = append(games, p.Games...)
+	}
+	return PGN{Games: Deduplicate(games, identity)}
+}
+
+// moves returns the moves of the game in SAN, without comments
+func (g *Game) moves() string {
+	moves := []string{}
+	for _, mt := range g.Movetext {
+		for _, m := range []Move{mt.White, mt.Black} {
+			if !m.IsZero() {
+				moves = append(moves, m.String())
+			}
+		}
+	}
+	return strings.Join(moves, " ")
+}
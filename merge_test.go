@@ -0,0 +1,61 @@
+package pgn_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestMerge(t *testing.T) {
+	a := "[White \"A\"]\n[Black \"B\"]\n[Result \"1-0\"]\n\n1. e4 e5 2. Nf3 Nc6 1-0\n\n" +
+		"[White \"C\"]\n[Black \"D\"]\n[Result \"1-0\"]\n\n1. e4 e5 2. Nf3 Nc6 1-0"
+	b := "[White \"A\"]\n[Black \"B\"]\n[Result \"1-0\"]\n\n1. e4 {best} e5 2. Nf3 Nc6 1-0\n\n" +
+		"[White \"E\"]\n[Black \"F\"]\n[Result \"1-0\"]\n\n1. Nf3 e5 2. e4 Nc6 1-0"
+
+	var pa, pb pgn.PGN
+	if err := pgn.Unmarshal(a, &pa); err != nil {
+		t.Fatal(err)
+	}
+	if err := pgn.Unmarshal(b, &pb); err != nil {
+		t.Fatal(err)
+	}
+
+	data := []struct {
+		identity pgn.Identity
+		whites   []string
+	}{
+		{pgn.IdentityMovetext, []string{"A", "A", "E"}},
+		{pgn.IdentityGame, []string{"A", "C", "E"}},
+		{pgn.IdentityFinalPosition, []string{"A"}},
+	}
+	for _, test := range data {
+		t.Run(test.identity.String(), func(t *testing.T) {
+			merged := pgn.Merge(test.identity, pa, pb)
+			whites := []string{}
+			for _, game := range merged.Games {
+				whites = append(whites, game.White())
+			}
+			if fmt.Sprint(whites) != fmt.Sprint(test.whites) {
+				fmt.Println("Got:", whites)
+				fmt.Println("Exp:", test.whites)
+				t.Fatal("Unexpected games")
+			}
+		})
+	}
+}
+
+func TestMergeFinalPositionEnPassant(t *testing.T) {
+	// the first game ends with a double step no pawn can capture, the second
+	// reaches the same position with single steps
+	var unmarshalled pgn.PGN
+	in := "[White \"A\"]\n\n1. Nf3 Nc6 2. e4 e5 *\n\n[White \"B\"]\n\n1. e3 e6 2. e4 Nc6 3. Nf3 e5 *"
+	if err := pgn.Unmarshal(in, &unmarshalled); err != nil {
+		t.Fatal(err)
+	}
+	merged := pgn.Merge(pgn.IdentityFinalPosition, unmarshalled)
+	if len(merged.Games) != 1 {
+		fmt.Println("Got:", len(merged.Games))
+		t.Fatal("Expected the games to be duplicates")
+	}
+}
@@ -0,0 +1,296 @@
+package pgn
+
+import (
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	ERR_QUERY_EXPRESSION = "Expected a comparison such as White = \"Carlsen\""
+	ERR_QUERY_OPERATOR   = "Expected a comparison operator: = != < <= > >= ~ !~"
+	ERR_QUERY_VALUE      = "Expected a quoted string or a number"
+	ERR_QUERY_PAREN      = "Expected a closing parenthesis"
+	ERR_QUERY_END        = "Expected and, or or the end of the query"
+	ERR_QUERY_STRING     = "String not closed"
+)
+
+// Names of the game properties a query can use besides tags
+const (
+	QueryPlies  = "plies"
+	QueryMoves  = "moves"
+	QueryResult = "result"
+)
+
+// Query is a parsed filter expression over the tags and properties of a game.
+//
+// A query is made of comparisons joined with and, or and not, and grouped with
+// parentheses:
+//
+//	White ~ "Carlsen" and Result = "1-0" and WhiteElo >= 2700 and plies > 40
+//
+// The left side of a comparison is a tag name, or one of the properties
+// plies, moves (full moves) and result (the termination marker). The right
+// side is a quoted string or a number. The operators are = != < <= > >= and ~
+// and !~, which test whether the value contains the string, ignoring case.
+// When both sides are numbers they are compared as numbers, otherwise as
+// strings. A comparison with a tag the game does not have is false, except
+// for != and !~.
+type Query struct {
+	root queryNode
+}
+
+// ParseQuery parses a query expression. Errors are *SyntaxError values whose
+// Pos is in the query.
+func ParseQuery(s string) (*Query, error) {
+	p := queryParser{scanner: NewScanner(s)}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if !isNul(p.scanner.Peek()) {
+		return nil, p.syntaxError(ERR_QUERY_END)
+	}
+	return &Query{root: root}, nil
+}
+
+// Match reports whether the game satisfies the query
+func (q *Query) Match(g *Game) bool {
+	return q.root.match(g)
+}
+
+type queryNode interface {
+	match(g *Game) bool
+}
+
+type queryAnd struct{ left, right queryNode }
+type queryOr struct{ left, right queryNode }
+type queryNot struct{ node queryNode }
+
+func (q queryAnd) match(g *Game) bool { return q.left.match(g) && q.right.match(g) }
+func (q queryOr) match(g *Game) bool  { return q.left.match(g) || q.right.match(g) }
+func (q queryNot) match(g *Game) bool { return !q.node.match(g) }
+
+type queryComparison struct {
+	name     string
+	operator string
+	value    string
+}
+
+func (q queryComparison) match(g *Game) bool {
+	value, ok := queryValue(g, q.name)
+	if !ok {
+		return q.operator == "!=" || q.operator == "!~"
+	}
+
+	switch q.operator {
+	case "~":
+		return strings.Contains(strings.ToLower(value), strings.ToLower(q.value))
+	case "!~":
+		return !strings.Contains(strings.ToLower(value), strings.ToLower(q.value))
+	}
+
+	cmp := strings.Compare(value, q.value)
+	a, errA := strconv.ParseFloat(value, 64)
+	b, errB := strconv.ParseFloat(q.value, 64)
+	if errA == nil && errB == nil {
+		switch {
+		case a < b:
+			cmp = -1
+		case a > b:
+			cmp = 1
+		default:
+			cmp = 0
+		}
+	}
+
+	switch q.operator {
+	case "=":
+		return cmp == 0
+	case "!=":
+		return cmp != 0
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+// queryValue returns the value of a tag or property of the game
+func queryValue(g *Game, name string) (string, bool) {
+	switch name {
+	case QueryPlies:
+		return strconv.Itoa(g.Plies()), true
+	case QueryMoves:
+		return strconv.Itoa((g.Plies() + 1) / 2), true
+	case QueryResult:
+		if g.Termination == "" {
+			return g.Tag(TagResult)
+		}
+		return string(g.Termination), true
+	}
+	return g.Tag(name)
+}
+
+type queryParser struct {
+	scanner Scanner
+}
+
+func (p *queryParser) syntaxError(message string) error {
+	return &SyntaxError{Pos: p.scanner.Pos(), Message: message}
+}
+
+// Rule: or = and , { "or" , and } ;
+func (p *queryParser) parseOr() (queryNode, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.readKeyword("or") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = queryOr{left, right}
+	}
+	return left, nil
+}
+
+// Rule: and = not , { "and" , not } ;
+func (p *queryParser) parseAnd() (queryNode, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.readKeyword("and") {
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = queryAnd{left, right}
+	}
+	return left, nil
+}
+
+// Rule: not = "not" , not | "(" , or , ")" | comparison ;
+func (p *queryParser) parseNot() (queryNode, error) {
+	if p.readKeyword("not") {
+		node, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return queryNot{node}, nil
+	}
+
+	p.skipSpace()
+	if p.scanner.Peek() == '(' {
+		p.scanner.Next()
+		node, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpace()
+		if p.scanner.Peek() != ')' {
+			return nil, p.syntaxError(ERR_QUERY_PAREN)
+		}
+		p.scanner.Next()
+		return node, nil
+	}
+
+	return p.parseComparison()
+}
+
+// Rule: comparison = name , operator , ( string | number ) ;
+func (p *queryParser) parseComparison() (queryNode, error) {
+	p.skipSpace()
+	name := p.readWord()
+	if name == "" {
+		return nil, p.syntaxError(ERR_QUERY_EXPRESSION)
+	}
+
+	p.skipSpace()
+	operator := ""
+	for _, op := range []string{"!=", "!~", "<=", ">=", "=", "<", ">", "~"} {
+		if p.scanner.HasPrefix(op) {
+			operator = op
+			break
+		}
+	}
+	if operator == "" {
+		return nil, p.syntaxError(ERR_QUERY_OPERATOR)
+	}
+	for range operator {
+		p.scanner.Next()
+	}
+
+	p.skipSpace()
+	var value string
+	if isDoubleQuote(p.scanner.Peek()) {
+		var err error
+		if value, err = p.readString(); err != nil {
+			return nil, err
+		}
+	} else {
+		pos := p.scanner.Pos()
+		value = p.readWord()
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return nil, &SyntaxError{Pos: pos, Message: ERR_QUERY_VALUE}
+		}
+	}
+
+	return queryComparison{name: name, operator: operator, value: value}, nil
+}
+
+// readString reads a double quoted string, in which a backslash escapes a
+// double quote or another backslash
+func (p *queryParser) readString() (string, error) {
+	p.scanner.Next()
+	s := ""
+	for {
+		r := p.scanner.Next()
+		switch {
+		case isNul(r):
+			return "", p.syntaxError(ERR_QUERY_STRING)
+		case isDoubleQuote(r):
+			return s, nil
+		case isBackslash(r) && (isDoubleQuote(p.scanner.Peek()) || isBackslash(p.scanner.Peek())):
+			r = p.scanner.Next()
+		}
+		s = s + string(r)
+	}
+}
+
+// readKeyword reads the keyword if it comes next, ignoring case
+func (p *queryParser) readKeyword(keyword string) bool {
+	p.skipSpace()
+	saved := p.scanner
+	if strings.EqualFold(p.readWord(), keyword) {
+		return true
+	}
+	p.scanner = saved
+	return false
+}
+
+// readWord reads a name or a number
+func (p *queryParser) readWord() string {
+	s := ""
+	for {
+		r := p.scanner.Peek()
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' && r != '-' {
+			return s
+		}
+		s = s + string(p.scanner.Next())
+	}
+}
+
+func (p *queryParser) skipSpace() {
+	for isWhiteSpace(p.scanner.Peek()) {
+		p.scanner.Next()
+	}
+}
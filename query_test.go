@@ -0,0 +1,74 @@
+package pgn_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestQuery(t *testing.T) {
+	in := "[White \"Carlsen, Magnus\"]\n[Black \"Nakamura, Hikaru\"]\n[Result \"1-0\"]\n" +
+		"[WhiteElo \"2850\"]\n[Date \"2019.03.01\"]\n\n1. e4 e5 2. Nf3 Nc6 3. Bb5 1-0"
+	var unmarshalled pgn.PGN
+	if err := pgn.Unmarshal(in, &unmarshalled); err != nil {
+		t.Fatal(err)
+	}
+	game := &unmarshalled.Games[0]
+
+	data := []struct {
+		query string
+		match bool
+	}{
+		{`White ~ "carlsen" and Result = "1-0" and WhiteElo >= 2700 and plies > 4`, true},
+		{`White ~ "Carlsen" and plies > 40`, false},
+		{`WhiteElo > 900`, true},
+		{`WhiteElo < 10000`, true},
+		{`BlackElo >= 2700`, false},
+		{`BlackElo != 2700`, true},
+		{`not (Black ~ "Caruana" or White ~ "Caruana")`, true},
+		{`Date >= "2019" AND Date < "2020"`, true},
+		{`moves = 3 and result = "1-0"`, true},
+		{`White !~ "Carlsen" or Black = "Nakamura, Hikaru"`, true},
+		{`Event = "\"Quoted\""`, false},
+	}
+	for _, test := range data {
+		t.Run(test.query, func(t *testing.T) {
+			q, err := pgn.ParseQuery(test.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := q.Match(game); got != test.match {
+				fmt.Println("Got:", got)
+				fmt.Println("Exp:", test.match)
+				t.Fatal("Unexpected match")
+			}
+		})
+	}
+}
+
+func TestParseQueryErrors(t *testing.T) {
+	data := []struct {
+		query string
+		err   string
+		col   int
+	}{
+		{``, pgn.ERR_QUERY_EXPRESSION, 1},
+		{`White`, pgn.ERR_QUERY_OPERATOR, 6},
+		{`White = Carlsen`, pgn.ERR_QUERY_VALUE, 9},
+		{`White = "Carlsen`, pgn.ERR_QUERY_STRING, 17},
+		{`(plies > 40`, pgn.ERR_QUERY_PAREN, 12},
+		{`plies > 40 xor plies < 80`, pgn.ERR_QUERY_END, 12},
+	}
+	for _, test := range data {
+		t.Run(test.query, func(t *testing.T) {
+			_, err := pgn.ParseQuery(test.query)
+			syntaxErr, ok := err.(*pgn.SyntaxError)
+			if !ok || syntaxErr.Message != test.err || syntaxErr.Pos.Column != test.col {
+				fmt.Println("Got:", err, syntaxErr)
+				fmt.Println("Exp:", test.err, test.col)
+				t.Fatal("Unexpected error")
+			}
+		})
+	}
+}
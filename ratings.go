@@ -0,0 +1,172 @@
+package pgn
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Names of the player rating and title tags
+const (
+	TagWhiteElo        = "WhiteElo"
+	TagBlackElo        = "BlackElo"
+	TagWhiteRatingDiff = "WhiteRatingDiff"
+	TagBlackRatingDiff = "BlackRatingDiff"
+	TagWhiteTitle      = "WhiteTitle"
+	TagBlackTitle      = "BlackTitle"
+	TagWhiteFideId     = "WhiteFideId"
+	TagBlackFideId     = "BlackFideId"
+	TagWhiteUSCF       = "WhiteUSCF"
+	TagBlackUSCF       = "BlackUSCF"
+)
+
+const (
+	ERR_RATING      = "Expected rating to be a non-negative integer"
+	ERR_RATING_DIFF = "Expected rating difference to be a signed integer"
+	ERR_TITLE       = "Unknown title"
+	ERR_FIDE_ID     = "Expected FIDE ID to be a positive integer"
+)
+
+// Title is a chess title as written in the WhiteTitle and BlackTitle tags
+type Title string
+
+const (
+	TitleGM  Title = "GM"
+	TitleIM  Title = "IM"
+	TitleFM  Title = "FM"
+	TitleCM  Title = "CM"
+	TitleWGM Title = "WGM"
+	TitleWIM Title = "WIM"
+	TitleWFM Title = "WFM"
+	TitleWCM Title = "WCM"
+	// TitleLM is a Lichess master
+	TitleLM Title = "LM"
+	// TitleBOT marks a computer account on online servers
+	TitleBOT Title = "BOT"
+	// TitleNone is the standard's "-" for a player without a title
+	TitleNone Title = "-"
+)
+
+var knownTitles = map[Title]bool{
+	TitleGM:   true,
+	TitleIM:   true,
+	TitleFM:   true,
+	TitleCM:   true,
+	TitleWGM:  true,
+	TitleWIM:  true,
+	TitleWFM:  true,
+	TitleWCM:  true,
+	TitleLM:   true,
+	TitleBOT:  true,
+	TitleNone: true,
+}
+
+// ParseRating parses the value of a rating tag such as WhiteElo. It reports
+// false for "?", "-" and "", which mark an unknown or missing rating.
+func ParseRating(s string) (int, bool, error) {
+	if s == "" || s == "?" || s == "-" {
+		return 0, false, nil
+	}
+	for _, r := range s {
+		if !isDigit(r) {
+			return 0, false, errors.New(ERR_RATING)
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false, errors.New(ERR_RATING)
+	}
+	return n, true, nil
+}
+
+// ParseRatingDiff parses the value of a rating difference tag such as
+// WhiteRatingDiff, for example "+8" or "-12"
+func ParseRatingDiff(s string) (int, bool, error) {
+	if s == "" || s == "?" || s == "-" {
+		return 0, false, nil
+	}
+	digits := s
+	if s[0] == '+' || s[0] == '-' {
+		digits = s[1:]
+	}
+	if digits == "" {
+		return 0, false, errors.New(ERR_RATING_DIFF)
+	}
+	for _, r := range digits {
+		if !isDigit(r) {
+			return 0, false, errors.New(ERR_RATING_DIFF)
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, false, errors.New(ERR_RATING_DIFF)
+	}
+	return n, true, nil
+}
+
+// ParseTitle parses the value of a title tag such as WhiteTitle. It reports
+// false for "" and "-", a player without a title.
+func ParseTitle(s string) (Title, bool, error) {
+	if s == "" || Title(s) == TitleNone {
+		return "", false, nil
+	}
+	t := Title(s)
+	if !knownTitles[t] {
+		return "", false, errors.New(ERR_TITLE)
+	}
+	return t, true, nil
+}
+
+// ParseFideId parses the value of a FIDE ID tag such as WhiteFideId
+func ParseFideId(s string) (int, bool, error) {
+	if s == "" || s == "?" || s == "-" {
+		return 0, false, nil
+	}
+	n, ok, err := ParseRating(s)
+	if err != nil || n == 0 {
+		return 0, false, errors.New(ERR_FIDE_ID)
+	}
+	return n, ok, nil
+}
+
+// WhiteElo returns White's Elo rating and reports false if it is unknown
+func (g *Game) WhiteElo() (int, bool, error) { return ParseRating(g.tagValue(TagWhiteElo)) }
+
+// BlackElo returns Black's Elo rating and reports false if it is unknown
+func (g *Game) BlackElo() (int, bool, error) { return ParseRating(g.tagValue(TagBlackElo)) }
+
+// WhiteRatingDiff returns the change in White's rating from the game
+func (g *Game) WhiteRatingDiff() (int, bool, error) {
+	return ParseRatingDiff(g.tagValue(TagWhiteRatingDiff))
+}
+
+// BlackRatingDiff returns the change in Black's rating from the game
+func (g *Game) BlackRatingDiff() (int, bool, error) {
+	return ParseRatingDiff(g.tagValue(TagBlackRatingDiff))
+}
+
+// WhiteTitle returns White's title and reports false if White has none
+func (g *Game) WhiteTitle() (Title, bool, error) { return ParseTitle(g.tagValue(TagWhiteTitle)) }
+
+// BlackTitle returns Black's title and reports false if Black has none
+func (g *Game) BlackTitle() (Title, bool, error) { return ParseTitle(g.tagValue(TagBlackTitle)) }
+
+// WhiteFideId returns White's FIDE ID and reports false if it is unknown
+func (g *Game) WhiteFideId() (int, bool, error) { return ParseFideId(g.tagValue(TagWhiteFideId)) }
+
+// BlackFideId returns Black's FIDE ID and reports false if it is unknown
+func (g *Game) BlackFideId() (int, bool, error) { return ParseFideId(g.tagValue(TagBlackFideId)) }
+
+// SetRatingTag sets a rating tag such as WhiteElo
+func (g *Game) SetRatingTag(name string, rating int) {
+	g.SetTag(name, strconv.Itoa(rating))
+}
+
+// SetRatingDiffTag sets a rating difference tag such as WhiteRatingDiff,
+// always writing the sign
+func (g *Game) SetRatingDiffTag(name string, diff int) {
+	s := strconv.Itoa(diff)
+	if diff >= 0 {
+		s = "+" + s
+	}
+	g.SetTag(name, s)
+}
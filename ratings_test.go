@@ -0,0 +1,75 @@
+package pgn_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestGameRatings(t *testing.T) {
+	game := pgn.Game{
+		TagPairs: []pgn.TagPair{
+			pgn.TagPair{Name: "WhiteElo", Value: "2785"},
+			pgn.TagPair{Name: "BlackElo", Value: "?"},
+			pgn.TagPair{Name: "WhiteRatingDiff", Value: "+8"},
+			pgn.TagPair{Name: "BlackRatingDiff", Value: "-12"},
+			pgn.TagPair{Name: "WhiteTitle", Value: "GM"},
+			pgn.TagPair{Name: "BlackTitle", Value: "-"},
+			pgn.TagPair{Name: "WhiteFideId", Value: "1503014"},
+		},
+	}
+
+	if elo, ok, err := game.WhiteElo(); err != nil || !ok || elo != 2785 {
+		fmt.Println(elo, ok, err)
+		t.Fatal("Unexpected WhiteElo")
+	}
+	if _, ok, err := game.BlackElo(); err != nil || ok {
+		t.Fatal("Expected unknown BlackElo")
+	}
+	if diff, ok, err := game.WhiteRatingDiff(); err != nil || !ok || diff != 8 {
+		t.Fatal("Unexpected WhiteRatingDiff")
+	}
+	if diff, ok, err := game.BlackRatingDiff(); err != nil || !ok || diff != -12 {
+		t.Fatal("Unexpected BlackRatingDiff")
+	}
+	if title, ok, err := game.WhiteTitle(); err != nil || !ok || title != pgn.TitleGM {
+		t.Fatal("Unexpected WhiteTitle")
+	}
+	if _, ok, err := game.BlackTitle(); err != nil || ok {
+		t.Fatal("Expected no BlackTitle")
+	}
+	if id, ok, err := game.WhiteFideId(); err != nil || !ok || id != 1503014 {
+		t.Fatal("Unexpected WhiteFideId")
+	}
+	if _, ok, err := game.BlackFideId(); err != nil || ok {
+		t.Fatal("Expected missing BlackFideId")
+	}
+
+	game.SetRatingTag(pgn.TagBlackElo, 2700)
+	game.SetRatingDiffTag(pgn.TagWhiteRatingDiff, 0)
+	if v, _ := game.Tag(pgn.TagBlackElo); v != "2700" {
+		t.Fatal("Unexpected BlackElo")
+	}
+	if v, _ := game.Tag(pgn.TagWhiteRatingDiff); v != "+0" {
+		t.Fatal("Unexpected WhiteRatingDiff")
+	}
+}
+
+func TestRatingValidation(t *testing.T) {
+	if _, _, err := pgn.ParseRating("2700a"); err == nil || err.Error() != pgn.ERR_RATING {
+		t.Fatal("Expected rating error")
+	}
+	if _, _, err := pgn.ParseRating("-5"); err == nil {
+		t.Fatal("Expected negative rating error")
+	}
+	if _, _, err := pgn.ParseRatingDiff("+"); err == nil || err.Error() != pgn.ERR_RATING_DIFF {
+		t.Fatal("Expected rating difference error")
+	}
+	if _, _, err := pgn.ParseTitle("Grandmaster"); err == nil || err.Error() != pgn.ERR_TITLE {
+		t.Fatal("Expected title error")
+	}
+	if _, _, err := pgn.ParseFideId("0"); err == nil || err.Error() != pgn.ERR_FIDE_ID {
+		t.Fatal("Expected FIDE ID error")
+	}
+}
@@ -0,0 +1,142 @@
+package pgn
+
+import (
+	"errors"
+	"strconv"
+)
+
+// Names of the tags that set up a starting position
+const (
+	TagSetUp = "SetUp"
+	TagFEN   = "FEN"
+)
+
+// Ply is one half move of a replayed game
+type Ply struct {
+	// Index counts half moves from 1
+	Index      int
+	MoveNumber int
+	Color      Color
+	Move       Move
+	// Position is the position after the move
+	Position Position
+}
+
+// Numbered writes the move of the ply with its number, such as "2... Nc6"
+func (ply Ply) Numbered() string {
+	return NumberedMove(ply.MoveNumber, ply.Color, ply.Move)
+}
+
+// NumberedMove writes a move with its number, such as "2. Nf3" for White's
+// move or "2... Nc6" for Black's
+func NumberedMove(number int, color Color, m Move) string {
+	dots := "."
+	if color == Black {
+		dots = "..."
+	}
+	return strconv.Itoa(number) + dots + " " + m.String()
+}
+
+// MoveError is an illegal or ambiguous move found while replaying a game
+type MoveError struct {
+	Ply     int
+	Color   Color
+	Number  int
+	Move    Move
+	Message string
+}
+
+func (e *MoveError) Error() string {
+	return e.Message + ": " + NumberedMove(e.Number, e.Color, e.Move)
+}
+
+// StartingPosition returns the position the game starts from: the position in
+// the FEN tag when the SetUp tag is "1", or the standard starting position
+func (g *Game) StartingPosition() (Position, error) {
+	fen, ok := g.Tag(TagFEN)
+	if !ok {
+		return NewPosition(), nil
+	}
+	if setUp, ok := g.Tag(TagSetUp); ok && setUp != "1" {
+		return NewPosition(), nil
+	}
+	return ParseFEN(fen)
+}
+
+// Replay plays the moves of the game from its starting position, calling fn
+// after each ply. It stops at the first illegal move, returning a *MoveError,
+// or at the first error fn returns.
+func (g *Game) Replay(fn func(ply Ply) error) error {
+	p, err := g.StartingPosition()
+	if err != nil {
+		return err
+	}
+
+	index := 0
+	play := func(number int, color Color, m Move) error {
+		if m.IsZero() {
+			return nil
+		}
+		if p.Turn != color {
+			return &MoveError{Ply: index + 1, Color: color, Number: number, Move: m, Message: ERR_MOVE_ORDER}
+		}
+		if err := p.Play(m); err != nil {
+			return &MoveError{Ply: index + 1, Color: color, Number: number, Move: m, Message: err.Error()}
+		}
+		index++
+		if fn == nil {
+			return nil
+		}
+		return fn(Ply{Index: index, MoveNumber: number, Color: color, Move: m, Position: p})
+	}
+
+	for _, mt := range g.Movetext {
+		if err := play(mt.Num, White, mt.White); err != nil {
+			return err
+		}
+		if err := play(mt.Num, Black, mt.Black); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FinalPosition replays the game and returns the position after its last
+// move, or the error Replay returns
+func (g *Game) FinalPosition() (Position, error) {
+	p, err := g.StartingPosition()
+	if err != nil {
+		return p, err
+	}
+	err = g.Replay(func(ply Ply) error {
+		p = ply.Position
+		return nil
+	})
+	return p, err
+}
+
+const ERR_MOVE_ORDER = "Move played out of turn"
+
+// ValidateMoves replays the game and reports the first illegal or ambiguous
+// move, or a FEN tag that cannot be parsed
+func ValidateMoves(g *Game) []Issue {
+	err := g.Replay(nil)
+	if err == nil {
+		return []Issue{}
+	}
+
+	var moveErr *MoveError
+	if errors.As(err, &moveErr) {
+		return []Issue{{
+			Severity: SeverityError,
+			Message:  moveErr.Error(),
+			Pos:      moveErr.Move.Pos,
+		}}
+	}
+	return []Issue{{
+		Severity: SeverityError,
+		Tag:      TagFEN,
+		Message:  err.Error(),
+		Pos:      g.tagPos(TagFEN),
+	}}
+}
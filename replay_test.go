@@ -0,0 +1,111 @@
+package pgn_test
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestReplay(t *testing.T) {
+	b, err := os.ReadFile("data/games/fischer_spassky_1992_11_04.pgn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var unmarshalled pgn.PGN
+	if err := pgn.Unmarshal(string(b), &unmarshalled); err != nil {
+		t.Fatal(err)
+	}
+	game := unmarshalled.Games[0]
+
+	plies := 0
+	var last pgn.Ply
+	err = game.Replay(func(ply pgn.Ply) error {
+		plies++
+		last = ply
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plies != 85 || plies != game.Plies() {
+		fmt.Println("Got:", plies)
+		t.Fatal("Unexpected total plies")
+	}
+	exp := "8/8/4R1p1/2k3p1/1p4P1/1P1b1P2/3K1n2/8 b - - 2 43"
+	if last.Position.FEN() != exp {
+		fmt.Println("Got:", last.Position.FEN())
+		fmt.Println("Exp:", exp)
+		t.Fatal("Unexpected final position")
+	}
+}
+
+func TestReplayFromFEN(t *testing.T) {
+	in := "[SetUp \"1\"]\n[FEN \"4k3/8/8/8/8/8/4P3/4K3 w - - 0 1\"]\n\n1. e4 Kd7 2. e5 *"
+	var unmarshalled pgn.PGN
+	if err := pgn.Unmarshal(in, &unmarshalled); err != nil {
+		t.Fatal(err)
+	}
+	issues := pgn.ValidateMoves(&unmarshalled.Games[0])
+	if len(issues) != 0 {
+		fmt.Println("Got:", issues)
+		t.Fatal("Unexpected issues")
+	}
+}
+
+func TestReplayBlackToMove(t *testing.T) {
+	in := "[SetUp \"1\"]\n[FEN \"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1\"]\n\n1... e5 2. Nf3 *"
+	var unmarshalled pgn.PGN
+	if err := pgn.Unmarshal(in, &unmarshalled); err != nil {
+		t.Fatal(err)
+	}
+	game := unmarshalled.Games[0]
+	if first := game.Movetext[0]; !first.White.IsZero() || first.Black.String() != "e5" {
+		fmt.Println("Got:", first)
+		t.Fatal("Expected Black's move in Black's place")
+	}
+	if issues := pgn.ValidateMoves(&game); len(issues) != 0 {
+		fmt.Println("Got:", issues)
+		t.Fatal("Unexpected issues")
+	}
+	exp := "[SetUp \"1\"]\n[FEN \"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1\"]\n\n1... e5 2. Nf3 *\n"
+	if got := pgn.Marshal(unmarshalled); got != exp {
+		fmt.Println("Got:", got)
+		fmt.Println("Exp:", exp)
+		t.Fatal("Unexpected PGN")
+	}
+}
+
+func TestValidateMoves(t *testing.T) {
+	in := "1. e4 e5 2. Ke3 Nf6 *"
+	var unmarshalled pgn.PGN
+	if err := pgn.Unmarshal(in, &unmarshalled); err != nil {
+		t.Fatal(err)
+	}
+	issues := pgn.ValidateMoves(&unmarshalled.Games[0])
+	exp := pgn.Issue{
+		Severity: pgn.SeverityError,
+		Message:  "Illegal move: 2. Ke3",
+		Pos:      pgn.Pos{Offset: 12, Line: 1, Column: 13},
+	}
+	if len(issues) != 1 || issues[0] != exp {
+		fmt.Println("Got:", issues)
+		fmt.Println("Exp:", exp)
+		t.Fatal("Unexpected issues")
+	}
+}
+
+func TestNumberedMove(t *testing.T) {
+	m := pgn.Move{Piece: pgn.PieceKnight, File: "c", Rank: 6}
+	if got := pgn.NumberedMove(2, pgn.Black, m); got != "2... Nc6" {
+		fmt.Println("Got:", got)
+		t.Fatal("Unexpected move")
+	}
+	m.File = "f"
+	m.Rank = 3
+	if got := pgn.NumberedMove(2, pgn.White, m); got != "2. Nf3" {
+		fmt.Println("Got:", got)
+		t.Fatal("Unexpected move")
+	}
+}
@@ -0,0 +1,97 @@
+package pgn
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const ERR_ROUND_FORMAT = "Expected round as dot separated numbers, ? or -"
+
+// PGNRound is the value of the Round tag. A round is usually a number but may
+// be hierarchical, as in "3.2.1" for the first game of the second match of the
+// third round. "?" marks an unknown round and "-" a game for which rounds do
+// not apply.
+type PGNRound struct {
+	Parts         []int
+	Unknown       bool
+	NotApplicable bool
+}
+
+// ParseRound parses the value of a Round tag
+func ParseRound(s string) (PGNRound, error) {
+	switch s {
+	case "?":
+		return PGNRound{Unknown: true}, nil
+	case "-":
+		return PGNRound{NotApplicable: true}, nil
+	}
+
+	r := PGNRound{}
+	for _, part := range strings.Split(s, ".") {
+		if part == "" {
+			return PGNRound{}, errors.New(ERR_ROUND_FORMAT)
+		}
+		for _, c := range part {
+			if !isDigit(c) {
+				return PGNRound{}, errors.New(ERR_ROUND_FORMAT)
+			}
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return PGNRound{}, errors.New(ERR_ROUND_FORMAT)
+		}
+		r.Parts = append(r.Parts, n)
+	}
+	return r, nil
+}
+
+// String formats the round as it is written in the Round tag
+func (r PGNRound) String() string {
+	if r.NotApplicable {
+		return "-"
+	}
+	if r.Unknown || len(r.Parts) == 0 {
+		return "?"
+	}
+	parts := make([]string, len(r.Parts))
+	for i, n := range r.Parts {
+		parts[i] = strconv.Itoa(n)
+	}
+	return strings.Join(parts, ".")
+}
+
+// Compare returns -1, 0 or 1 as r comes before, with, or after o. Rounds are
+// ordered numerically part by part, so "2" < "10" and "3" < "3.1" < "3.2" < "4".
+// Unknown rounds and rounds that do not apply sort before numbered rounds.
+func (r PGNRound) Compare(o PGNRound) int {
+	for i := 0; i < len(r.Parts) && i < len(o.Parts); i++ {
+		if r.Parts[i] < o.Parts[i] {
+			return -1
+		}
+		if r.Parts[i] > o.Parts[i] {
+			return 1
+		}
+	}
+	switch {
+	case len(r.Parts) < len(o.Parts):
+		return -1
+	case len(r.Parts) > len(o.Parts):
+		return 1
+	}
+	return 0
+}
+
+// RoundTag parses the Round tag. A missing tag gives an unknown round.
+func (g *Game) RoundTag() (PGNRound, error) {
+	value, ok := g.Tag(TagRound)
+	if !ok {
+		return PGNRound{Unknown: true}, nil
+	}
+	return ParseRound(value)
+}
+
+// SetRoundTag sets the Round tag
+func (g *Game) SetRoundTag(r PGNRound) {
+	g.SetTag(TagRound, r.String())
+}
@@ -0,0 +1,87 @@
+package pgn_test
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestParseRound(t *testing.T) {
+	data := []struct {
+		in           string
+		out          pgn.PGNRound
+		errorMessage string
+	}{
+		{in: "29", out: pgn.PGNRound{Parts: []int{29}}},
+		{in: "3.2.1", out: pgn.PGNRound{Parts: []int{3, 2, 1}}},
+		{in: "?", out: pgn.PGNRound{Unknown: true}},
+		{in: "-", out: pgn.PGNRound{NotApplicable: true}},
+		{in: "", errorMessage: pgn.ERR_ROUND_FORMAT},
+		{in: "3.", errorMessage: pgn.ERR_ROUND_FORMAT},
+		{in: "R1", errorMessage: pgn.ERR_ROUND_FORMAT},
+	}
+	for _, test := range data {
+		t.Run(test.in, func(t *testing.T) {
+			got, err := pgn.ParseRound(test.in)
+			if test.errorMessage != "" {
+				if err == nil || err.Error() != test.errorMessage {
+					fmt.Println(err)
+					t.Fatal("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				fmt.Println(err)
+				t.Fatal("Unexpected error")
+			}
+			if !reflect.DeepEqual(got, test.out) {
+				fmt.Println("Got:", got)
+				fmt.Println("Exp:", test.out)
+				t.Fatal("Unexpected round")
+			}
+			if got.String() != test.in {
+				fmt.Println("Got:", got.String())
+				t.Fatal("Round did not format back to its input")
+			}
+		})
+	}
+}
+
+func TestPGNRoundCompare(t *testing.T) {
+	in := []string{"10", "3.2", "2", "?", "3", "3.10", "3.2.1"}
+	rounds := []pgn.PGNRound{}
+	for _, s := range in {
+		r, err := pgn.ParseRound(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rounds = append(rounds, r)
+	}
+	sort.SliceStable(rounds, func(i, j int) bool { return rounds[i].Compare(rounds[j]) < 0 })
+
+	got := []string{}
+	for _, r := range rounds {
+		got = append(got, r.String())
+	}
+	exp := []string{"?", "2", "3", "3.2", "3.2.1", "3.10", "10"}
+	if !reflect.DeepEqual(got, exp) {
+		fmt.Println("Got:", got)
+		fmt.Println("Exp:", exp)
+		t.Fatal("Unexpected order")
+	}
+}
+
+func TestGameRoundTag(t *testing.T) {
+	game := pgn.Game{}
+	r, err := game.RoundTag()
+	if err != nil || !r.Unknown {
+		t.Fatal("Expected missing Round to be unknown")
+	}
+	game.SetRoundTag(pgn.PGNRound{Parts: []int{29}})
+	if game.Round() != "29" {
+		t.Fatal("Unexpected Round")
+	}
+}
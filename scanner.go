@@ -1,5 +1,10 @@
 package pgn
 
+import (
+	"strings"
+	"unicode/utf8"
+)
+
 // Notes
 // https://blog.golang.org/strings
 // In Go, a string is a read-only and arbitrary slice of bytes.
@@ -13,15 +18,35 @@ package pgn
 type Scanner struct {
 	stream string
 	index  int
+	line   int
+	column int
+}
+
+// Pos is a position in the input stream. Offset is in bytes, Line and Column
+// are 1-based and Column counts runes. A CR LF pair counts as a single line break, as does a
+// lone CR, so positions are the same whatever line ending the input uses.
+type Pos struct {
+	Offset int
+	Line   int
+	Column int
 }
 
 // NewScanner returns an instance of Scanner
 func NewScanner(in string) Scanner {
 	return Scanner{
 		stream: in,
+		line:   1,
+		column: 1,
 	}
 }
 
+// NewScannerEncoding returns an instance of Scanner over in, which is
+// transcoded to UTF-8 from the given encoding. Pass EncodingAuto to detect the
+// encoding from a byte order mark or the bytes themselves.
+func NewScannerEncoding(in []byte, enc Encoding) Scanner {
+	return NewScanner(Decode(in, enc))
+}
+
 // Peek returns the next rune without discarding the current
 func (s *Scanner) Peek() rune {
 	// A "for range" loop is used because it decodes one UTF-8-encoded rune on
@@ -32,12 +57,42 @@ func (s *Scanner) Peek() rune {
 	return NUL
 }
 
+// Next returns the next rune and moves past it. Bytes that are not valid
+// UTF-8 are returned one at a time as utf8.RuneError.
 func (s *Scanner) Next() rune {
-	for _, r := range s.stream[s.index:] {
-		s.index++
-		return r
+	if s.index >= len(s.stream) {
+		return NUL
+	}
+	r, size := utf8.DecodeRuneInString(s.stream[s.index:])
+	s.index += size
+	s.advance(r)
+	return r
+}
+
+// HasPrefix reports whether the unread part of the stream begins with prefix
+func (s *Scanner) HasPrefix(prefix string) bool {
+	return strings.HasPrefix(s.stream[s.index:], prefix)
+}
+
+// Pos returns the position of the rune that the next call to Next will return
+func (s *Scanner) Pos() Pos {
+	return Pos{
+		Offset: s.index,
+		Line:   s.line,
+		Column: s.column,
+	}
+}
+
+func (s *Scanner) advance(r rune) {
+	switch {
+	case r == '\r' && s.Peek() == '\n':
+		// the line break is counted when the LF is read
+	case r == '\n' || r == '\r':
+		s.line++
+		s.column = 1
+	default:
+		s.column++
 	}
-	return NUL
 }
 
 const NUL = rune(0)
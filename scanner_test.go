@@ -1,6 +1,7 @@
 package pgn_test
 
 import (
+	"fmt"
 	"testing"
 
 	pgn "github.com/miketmoore/pgn"
@@ -29,3 +30,29 @@ func TestScannerNext(t *testing.T) {
 		t.Fatal("Next failed")
 	}
 }
+
+func TestScannerPos(t *testing.T) {
+	data := []struct {
+		name string
+		in   string
+		out  pgn.Pos
+	}{
+		{name: "LF", in: "ab\ncd", out: pgn.Pos{Offset: 5, Line: 2, Column: 3}},
+		{name: "CR LF", in: "ab\r\ncd", out: pgn.Pos{Offset: 6, Line: 2, Column: 3}},
+		{name: "CR", in: "ab\rcd", out: pgn.Pos{Offset: 5, Line: 2, Column: 3}},
+		{name: "Tab", in: "\tab", out: pgn.Pos{Offset: 3, Line: 1, Column: 4}},
+		{name: "Multi-byte", in: "Café", out: pgn.Pos{Offset: 5, Line: 1, Column: 5}},
+	}
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			s := pgn.NewScanner(test.in)
+			for s.Next() != pgn.NUL {
+			}
+			if s.Pos() != test.out {
+				fmt.Println("Got:", s.Pos())
+				fmt.Println("Exp:", test.out)
+				t.Fatal("Unexpected position")
+			}
+		})
+	}
+}
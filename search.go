@@ -0,0 +1,162 @@
+package pgn
+
+import (
+	"errors"
+	"strings"
+)
+
+const ERR_PATTERN = "Expected a board pattern of eight ranks, such as a FEN placement with ? for any square"
+
+// PositionKey identifies a position for search and repetition: the placement
+// of the pieces, the side to move, castling rights and the en passant square.
+// Keys are comparable and may be used as map keys.
+type PositionKey struct {
+	board     [64]byte
+	castling  int
+	enPassant int
+	turn      Color
+}
+
+// Key returns the key of the position. The en passant square only counts when
+// a pawn of the side to move stands beside the pawn that just moved, so the
+// same position reached by different moves has the same key.
+func (p *Position) Key() PositionKey {
+	return PositionKey{
+		board:     p.board,
+		castling:  p.castling,
+		enPassant: p.capturableEnPassant(),
+		turn:      p.Turn,
+	}
+}
+
+// capturableEnPassant returns the en passant square if a pawn of the side to
+// move can capture onto it, or -1
+func (p *Position) capturableEnPassant() int {
+	if p.enPassant < 0 {
+		return -1
+	}
+	pawn, ranks := byte('P'), -1
+	if p.Turn == Black {
+		pawn, ranks = 'p', 1
+	}
+	for _, files := range []int{-1, 1} {
+		if sq, ok := offset(p.enPassant, files, ranks); ok && p.board[sq] == pawn {
+			return p.enPassant
+		}
+	}
+	return -1
+}
+
+// PositionPattern matches positions by some of their squares. It is written
+// like the placement field of a FEN, rank 8 first, with ? standing for a
+// square that may hold anything, and may be followed by the side to move:
+//
+//	r???k??r/8/8/8/8/8/8/R???K??R w
+type PositionPattern struct {
+	// squares holds a FEN letter, '.' for an empty square or 0 for any
+	squares [64]byte
+	turn    *Color
+}
+
+// ParsePositionPattern parses a position pattern
+func ParsePositionPattern(s string) (PositionPattern, error) {
+	fields := strings.Fields(s)
+	if len(fields) < 1 || len(fields) > 2 {
+		return PositionPattern{}, errors.New(ERR_PATTERN)
+	}
+
+	pp := PositionPattern{}
+	ranks := strings.Split(fields[0], "/")
+	if len(ranks) != 8 {
+		return PositionPattern{}, errors.New(ERR_PATTERN)
+	}
+	for i, rank := range ranks {
+		r := 7 - i
+		f := 0
+		for _, c := range rank {
+			switch {
+			case c >= '1' && c <= '8':
+				for n := 0; n < int(c-'0') && f+n < 8; n++ {
+					pp.squares[r*8+f+n] = '.'
+				}
+				f += int(c - '0')
+			case c == '?' || strings.ContainsRune("PNBRQKpnbrqk", c):
+				if f > 7 {
+					return PositionPattern{}, errors.New(ERR_PATTERN)
+				}
+				if c != '?' {
+					pp.squares[r*8+f] = byte(c)
+				}
+				f++
+			default:
+				return PositionPattern{}, errors.New(ERR_PATTERN)
+			}
+		}
+		if f != 8 {
+			return PositionPattern{}, errors.New(ERR_PATTERN)
+		}
+	}
+
+	if len(fields) == 2 {
+		turn := White
+		switch fields[1] {
+		case "w":
+		case "b":
+			turn = Black
+		default:
+			return PositionPattern{}, errors.New(ERR_PATTERN)
+		}
+		pp.turn = &turn
+	}
+	return pp, nil
+}
+
+// Match reports whether the position fits the pattern
+func (pp PositionPattern) Match(p *Position) bool {
+	if pp.turn != nil && *pp.turn != p.Turn {
+		return false
+	}
+	for i, c := range pp.squares {
+		switch {
+		case c == 0:
+		case c == '.':
+			if p.board[i] != 0 {
+				return false
+			}
+		case p.board[i] != c:
+			return false
+		}
+	}
+	return true
+}
+
+// FindPositions replays the game and returns every ply after which the
+// position matches, along with the starting position as a ply with Index 0
+// if it matches. Moves after an illegal move are not searched.
+func (g *Game) FindPositions(match func(p *Position) bool) []Ply {
+	plies := []Ply{}
+	start, err := g.StartingPosition()
+	if err != nil {
+		return plies
+	}
+	if match(&start) {
+		plies = append(plies, Ply{MoveNumber: start.FullmoveNumber, Color: start.Turn, Position: start})
+	}
+	g.Replay(func(ply Ply) error {
+		if match(&ply.Position) {
+			plies = append(plies, ply)
+		}
+		return nil
+	})
+	return plies
+}
+
+// SamePosition returns a match function for FindPositions that finds the
+// position p. Positions are compared by hash, and by key only when the hashes
+// agree.
+func SamePosition(p Position) func(*Position) bool {
+	hash, key := p.Hash(), p.Key()
+	return func(q *Position) bool {
+		return q.Hash() == hash && q.Key() == key
+	}
+}
@@ -0,0 +1,72 @@
+package pgn_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestFindPositions(t *testing.T) {
+	// both games transpose into the same position after 2... Nc6, and the
+	// en passant square left by e5 does not count as no pawn can take on e6
+	in := "1. e4 e5 2. Nf3 Nc6 *\n\n1. Nf3 Nc6 2. e4 e5 *"
+	var unmarshalled pgn.PGN
+	if err := pgn.Unmarshal(in, &unmarshalled); err != nil {
+		t.Fatal(err)
+	}
+	target, err := pgn.ParseFEN("r1bqkbnr/pppp1ppp/2n5/4p3/4P3/5N2/PPPP1PPP/RNBQKB1R w KQkq - 2 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range unmarshalled.Games {
+		plies := unmarshalled.Games[i].FindPositions(pgn.SamePosition(target))
+		if len(plies) != 1 || plies[0].Index != 4 {
+			fmt.Println("Got:", plies)
+			t.Fatalf("Unexpected plies in game %d", i+1)
+		}
+	}
+
+	start := unmarshalled.Games[0].FindPositions(pgn.SamePosition(pgn.NewPosition()))
+	if len(start) != 1 || start[0].Index != 0 {
+		fmt.Println("Got:", start)
+		t.Fatal("Expected the starting position")
+	}
+}
+
+func TestPositionPattern(t *testing.T) {
+	data := []struct {
+		pattern string
+		fen     string
+		match   bool
+	}{
+		{"????k???/8/8/8/8/8/8/????K???", "4k3/8/8/8/8/8/8/4K3 w - - 0 1", true},
+		{"????k???/8/8/8/8/8/8/????K???", "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1", false},
+		{"????????/????????/????????/????????/????????/????????/????P???/???????? b", pgn.StartingFEN, false},
+		{"????????/????????/????????/????????/????????/????????/????P???/????????", pgn.StartingFEN, true},
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w", pgn.StartingFEN, true},
+	}
+	for _, test := range data {
+		t.Run(test.pattern, func(t *testing.T) {
+			pp, err := pgn.ParsePositionPattern(test.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := pgn.ParseFEN(test.fen)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := pp.Match(&p); got != test.match {
+				fmt.Println("Got:", got)
+				t.Fatal("Unexpected match")
+			}
+		})
+	}
+
+	for _, bad := range []string{"", "8/8/8", "9/8/8/8/8/8/8/8", "8/8/8/8/8/8/8/8 x"} {
+		if _, err := pgn.ParsePositionPattern(bad); err == nil || err.Error() != pgn.ERR_PATTERN {
+			fmt.Println("Got:", err)
+			t.Fatalf("Expected an error for %q", bad)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package pgn
+
+// Names of the Seven Tag Roster, in the order the standard exports them
+const (
+	TagEvent  = "Event"
+	TagSite   = "Site"
+	TagDate   = "Date"
+	TagRound  = "Round"
+	TagWhite  = "White"
+	TagBlack  = "Black"
+	TagResult = "Result"
+)
+
+// SevenTagRoster lists the tags every archival game has, in export order
+var SevenTagRoster = []string{
+	TagEvent,
+	TagSite,
+	TagDate,
+	TagRound,
+	TagWhite,
+	TagBlack,
+	TagResult,
+}
+
+// Tag returns the value of the first tag pair with the given name. Tag names
+// are case sensitive.
+func (g *Game) Tag(name string) (string, bool) {
+	for _, tp := range g.TagPairs {
+		if tp.Name == name {
+			return tp.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetTag sets the value of the first tag pair with the given name, keeping its
+// place in the tag section, or appends a new tag pair if there is none
+func (g *Game) SetTag(name, value string) {
+	for i, tp := range g.TagPairs {
+		if tp.Name == name {
+			g.TagPairs[i].Value = value
+			return
+		}
+	}
+	g.TagPairs = append(g.TagPairs, TagPair{Name: name, Value: value})
+}
+
+// DeleteTag removes every tag pair with the given name and reports whether
+// there was one
+func (g *Game) DeleteTag(name string) bool {
+	found := false
+	tagPairs := g.TagPairs[:0]
+	for _, tp := range g.TagPairs {
+		if tp.Name == name {
+			found = true
+			continue
+		}
+		tagPairs = append(tagPairs, tp)
+	}
+	g.TagPairs = tagPairs
+	return found
+}
+
+// DuplicateTags returns the names that appear in more than one tag pair, in
+// the order their second occurrence appears
+func (g *Game) DuplicateTags() []string {
+	seen := map[string]int{}
+	duplicates := []string{}
+	for _, tp := range g.TagPairs {
+		seen[tp.Name]++
+		if seen[tp.Name] == 2 {
+			duplicates = append(duplicates, tp.Name)
+		}
+	}
+	return duplicates
+}
+
+// Event returns the value of the Event tag, or "" if there is none
+func (g *Game) Event() string { return g.tagValue(TagEvent) }
+
+// Site returns the value of the Site tag, or "" if there is none
+func (g *Game) Site() string { return g.tagValue(TagSite) }
+
+// Date returns the value of the Date tag, or "" if there is none
+func (g *Game) Date() string { return g.tagValue(TagDate) }
+
+// Round returns the value of the Round tag, or "" if there is none
+func (g *Game) Round() string { return g.tagValue(TagRound) }
+
+// White returns the value of the White tag, or "" if there is none
+func (g *Game) White() string { return g.tagValue(TagWhite) }
+
+// Black returns the value of the Black tag, or "" if there is none
+func (g *Game) Black() string { return g.tagValue(TagBlack) }
+
+// Result returns the value of the Result tag, or "" if there is none
+func (g *Game) Result() string { return g.tagValue(TagResult) }
+
+func (g *Game) tagValue(name string) string {
+	value, _ := g.Tag(name)
+	return value
+}
@@ -0,0 +1,71 @@
+package pgn_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestGameTags(t *testing.T) {
+	game := pgn.Game{
+		TagPairs: []pgn.TagPair{
+			pgn.TagPair{Name: "Event", Value: "F/S Return Match"},
+			pgn.TagPair{Name: "Site", Value: "Belgrade, Serbia JUG"},
+			pgn.TagPair{Name: "Date", Value: "1992.11.04"},
+			pgn.TagPair{Name: "Round", Value: "29"},
+			pgn.TagPair{Name: "White", Value: "Fischer, Robert J."},
+			pgn.TagPair{Name: "Black", Value: "Spassky, Boris V."},
+			pgn.TagPair{Name: "Result", Value: "1/2-1/2"},
+		},
+	}
+
+	got := []string{game.Event(), game.Site(), game.Date(), game.Round(), game.White(), game.Black(), game.Result()}
+	exp := []string{"F/S Return Match", "Belgrade, Serbia JUG", "1992.11.04", "29", "Fischer, Robert J.", "Spassky, Boris V.", "1/2-1/2"}
+	if !reflect.DeepEqual(got, exp) {
+		fmt.Println("Got:", got)
+		fmt.Println("Exp:", exp)
+		t.Fatal("Unexpected Seven Tag Roster values")
+	}
+
+	if _, ok := game.Tag("event"); ok {
+		t.Fatal("Tag names should be case sensitive")
+	}
+
+	game.SetTag("Site", "Belgrade")
+	game.SetTag("Annotator", "Kasparov")
+	game.DeleteTag("Round")
+	names := []string{}
+	for _, tp := range game.TagPairs {
+		names = append(names, tp.Name)
+	}
+	exp = []string{"Event", "Site", "Date", "White", "Black", "Result", "Annotator"}
+	if !reflect.DeepEqual(names, exp) {
+		fmt.Println("Got:", names)
+		fmt.Println("Exp:", exp)
+		t.Fatal("Tag order not preserved")
+	}
+	if game.Site() != "Belgrade" || game.Round() != "" {
+		t.Fatal("Unexpected tag values after edit")
+	}
+}
+
+func TestGameDuplicateTags(t *testing.T) {
+	game := pgn.Game{
+		TagPairs: []pgn.TagPair{
+			pgn.TagPair{Name: "White", Value: "Tal"},
+			pgn.TagPair{Name: "Black", Value: "Botvinnik"},
+			pgn.TagPair{Name: "White", Value: "Tal, M."},
+			pgn.TagPair{Name: "White", Value: "Tal, Mikhail"},
+		},
+	}
+	got := game.DuplicateTags()
+	if !reflect.DeepEqual(got, []string{"White"}) {
+		fmt.Println("Got:", got)
+		t.Fatal("Unexpected duplicate tags")
+	}
+	if game.White() != "Tal" {
+		t.Fatal("Expected the first White tag to win")
+	}
+}
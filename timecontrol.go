@@ -0,0 +1,196 @@
+package pgn
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const TagTimeControl = "TimeControl"
+
+const ERR_TIME_CONTROL_FORMAT = "Expected time control descriptors such as 40/7200:3600, 300+2 or *180"
+
+// TimeControl is the value of the TimeControl tag. "?" marks an unknown time
+// control and "-" a game played without one. Otherwise the game is played in
+// one or more periods, each described by a TimeControlPeriod.
+type TimeControl struct {
+	Unknown bool
+	None    bool
+	Periods []TimeControlPeriod
+}
+
+// TimeControlPeriod is one descriptor of a TimeControl tag
+//
+//	40/7200  Moves: 40, Time: 2h, the player must make 40 moves in two hours
+//	3600     Time: 1h, sudden death, the rest of the game in one hour
+//	300+2    Time: 5m, Increment: 2s, two seconds are added after every move
+//	*180     Time: 3m, Sandclock, the opponent's clock gains what the player's loses
+//
+// Moves is zero for a period that lasts the rest of the game.
+type TimeControlPeriod struct {
+	Moves     int
+	Time      time.Duration
+	Increment time.Duration
+	Sandclock bool
+}
+
+// ParseTimeControl parses the value of a TimeControl tag
+func ParseTimeControl(s string) (TimeControl, error) {
+	switch s {
+	case "?":
+		return TimeControl{Unknown: true}, nil
+	case "-":
+		return TimeControl{None: true}, nil
+	}
+
+	tc := TimeControl{}
+	for _, descriptor := range strings.Split(s, ":") {
+		period, err := parseTimeControlPeriod(descriptor)
+		if err != nil {
+			return TimeControl{}, err
+		}
+		tc.Periods = append(tc.Periods, period)
+	}
+	return tc, nil
+}
+
+func parseTimeControlPeriod(s string) (TimeControlPeriod, error) {
+	p := TimeControlPeriod{}
+
+	if strings.HasPrefix(s, "*") {
+		seconds, err := parseSeconds(s[1:])
+		if err != nil {
+			return p, err
+		}
+		p.Time = seconds
+		p.Sandclock = true
+		return p, nil
+	}
+
+	if i := strings.Index(s, "/"); i >= 0 {
+		moves, err := parseTimeControlNumber(s[:i])
+		if err != nil {
+			return p, err
+		}
+		if moves == 0 {
+			return p, errors.New(ERR_TIME_CONTROL_FORMAT)
+		}
+		p.Moves = moves
+		s = s[i+1:]
+	}
+
+	if i := strings.Index(s, "+"); i >= 0 {
+		increment, err := parseSeconds(s[i+1:])
+		if err != nil {
+			return p, err
+		}
+		p.Increment = increment
+		s = s[:i]
+	}
+
+	seconds, err := parseSeconds(s)
+	if err != nil {
+		return p, err
+	}
+	p.Time = seconds
+	return p, nil
+}
+
+func parseSeconds(s string) (time.Duration, error) {
+	n, err := parseTimeControlNumber(s)
+	return time.Duration(n) * time.Second, err
+}
+
+func parseTimeControlNumber(s string) (int, error) {
+	if s == "" {
+		return 0, errors.New(ERR_TIME_CONTROL_FORMAT)
+	}
+	for _, r := range s {
+		if !isDigit(r) {
+			return 0, errors.New(ERR_TIME_CONTROL_FORMAT)
+		}
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, errors.New(ERR_TIME_CONTROL_FORMAT)
+	}
+	return n, nil
+}
+
+// String formats the time control as it is written in the TimeControl tag
+func (tc TimeControl) String() string {
+	if tc.None {
+		return "-"
+	}
+	if tc.Unknown || len(tc.Periods) == 0 {
+		return "?"
+	}
+	descriptors := make([]string, len(tc.Periods))
+	for i, p := range tc.Periods {
+		descriptors[i] = p.String()
+	}
+	return strings.Join(descriptors, ":")
+}
+
+// String formats the period as a TimeControl descriptor
+func (p TimeControlPeriod) String() string {
+	seconds := strconv.Itoa(int(p.Time / time.Second))
+	if p.Sandclock {
+		return "*" + seconds
+	}
+	s := seconds
+	if p.Moves > 0 {
+		s = strconv.Itoa(p.Moves) + "/" + s
+	}
+	if p.Increment > 0 {
+		s = s + "+" + strconv.Itoa(int(p.Increment/time.Second))
+	}
+	return s
+}
+
+// EstimatedDuration estimates the time each player has for a game of 40
+// moves: the time of the first period plus 40 increments. It is the usual
+// measure for classing a game as bullet, blitz, rapid or classical.
+func (tc TimeControl) EstimatedDuration() time.Duration {
+	if len(tc.Periods) == 0 {
+		return 0
+	}
+	p := tc.Periods[0]
+	return p.Time + 40*p.Increment
+}
+
+// Period returns the period in force for the given move number, which starts
+// at 1, and reports false if the time control has no periods
+func (tc TimeControl) Period(moveNumber int) (TimeControlPeriod, bool) {
+	if len(tc.Periods) == 0 {
+		return TimeControlPeriod{}, false
+	}
+	moves := 0
+	for _, p := range tc.Periods {
+		if p.Moves == 0 {
+			return p, true
+		}
+		moves += p.Moves
+		if moveNumber <= moves {
+			return p, true
+		}
+	}
+	// the standard repeats the last period once every period is used up
+	return tc.Periods[len(tc.Periods)-1], true
+}
+
+// TimeControlTag parses the TimeControl tag. A missing tag gives an unknown
+// time control.
+func (g *Game) TimeControlTag() (TimeControl, error) {
+	value, ok := g.Tag(TagTimeControl)
+	if !ok {
+		return TimeControl{Unknown: true}, nil
+	}
+	return ParseTimeControl(value)
+}
+
+// SetTimeControlTag sets the TimeControl tag
+func (g *Game) SetTimeControlTag(tc TimeControl) {
+	g.SetTag(TagTimeControl, tc.String())
+}
@@ -0,0 +1,118 @@
+package pgn_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestParseTimeControl(t *testing.T) {
+	data := []struct {
+		in           string
+		out          pgn.TimeControl
+		errorMessage string
+	}{
+		{in: "?", out: pgn.TimeControl{Unknown: true}},
+		{in: "-", out: pgn.TimeControl{None: true}},
+		{
+			in: "40/7200:3600",
+			out: pgn.TimeControl{Periods: []pgn.TimeControlPeriod{
+				pgn.TimeControlPeriod{Moves: 40, Time: 2 * time.Hour},
+				pgn.TimeControlPeriod{Time: time.Hour},
+			}},
+		},
+		{
+			in: "300",
+			out: pgn.TimeControl{Periods: []pgn.TimeControlPeriod{
+				pgn.TimeControlPeriod{Time: 5 * time.Minute},
+			}},
+		},
+		{
+			in: "300+2",
+			out: pgn.TimeControl{Periods: []pgn.TimeControlPeriod{
+				pgn.TimeControlPeriod{Time: 5 * time.Minute, Increment: 2 * time.Second},
+			}},
+		},
+		{
+			in: "*180",
+			out: pgn.TimeControl{Periods: []pgn.TimeControlPeriod{
+				pgn.TimeControlPeriod{Time: 3 * time.Minute, Sandclock: true},
+			}},
+		},
+		{
+			in: "40/5400+30:1800+30",
+			out: pgn.TimeControl{Periods: []pgn.TimeControlPeriod{
+				pgn.TimeControlPeriod{Moves: 40, Time: 90 * time.Minute, Increment: 30 * time.Second},
+				pgn.TimeControlPeriod{Time: 30 * time.Minute, Increment: 30 * time.Second},
+			}},
+		},
+		{in: "", errorMessage: pgn.ERR_TIME_CONTROL_FORMAT},
+		{in: "40/", errorMessage: pgn.ERR_TIME_CONTROL_FORMAT},
+		{in: "0/60", errorMessage: pgn.ERR_TIME_CONTROL_FORMAT},
+		{in: "5m+2s", errorMessage: pgn.ERR_TIME_CONTROL_FORMAT},
+		{in: "300:", errorMessage: pgn.ERR_TIME_CONTROL_FORMAT},
+	}
+	for _, test := range data {
+		t.Run(test.in, func(t *testing.T) {
+			got, err := pgn.ParseTimeControl(test.in)
+			if test.errorMessage != "" {
+				if err == nil || err.Error() != test.errorMessage {
+					fmt.Println(err)
+					t.Fatal("Expected error")
+				}
+				return
+			}
+			if err != nil {
+				fmt.Println(err)
+				t.Fatal("Unexpected error")
+			}
+			if !reflect.DeepEqual(got, test.out) {
+				fmt.Println("Got:", got)
+				fmt.Println("Exp:", test.out)
+				t.Fatal("Unexpected time control")
+			}
+			if got.String() != test.in {
+				fmt.Println("Got:", got.String())
+				t.Fatal("Time control did not format back to its input")
+			}
+		})
+	}
+}
+
+func TestTimeControlPeriod(t *testing.T) {
+	tc, err := pgn.ParseTimeControl("40/7200:20/3600:900")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []struct {
+		moveNumber int
+		out        time.Duration
+	}{
+		{moveNumber: 1, out: 2 * time.Hour},
+		{moveNumber: 40, out: 2 * time.Hour},
+		{moveNumber: 41, out: time.Hour},
+		{moveNumber: 61, out: 15 * time.Minute},
+		{moveNumber: 200, out: 15 * time.Minute},
+	}
+	for _, test := range data {
+		p, ok := tc.Period(test.moveNumber)
+		if !ok || p.Time != test.out {
+			fmt.Println("Move:", test.moveNumber, "Got:", p)
+			t.Fatal("Unexpected period")
+		}
+	}
+}
+
+func TestTimeControlEstimatedDuration(t *testing.T) {
+	tc, err := pgn.ParseTimeControl("180+2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tc.EstimatedDuration() != 260*time.Second {
+		fmt.Println("Got:", tc.EstimatedDuration())
+		t.Fatal("Unexpected estimated duration")
+	}
+}
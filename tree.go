@@ -0,0 +1,288 @@
+package pgn
+
+import (
+	"strings"
+)
+
+// Token types of the concrete syntax tree built by Lexer.TokenizeTree. The
+// first group are nodes, whose text is that of their children; the rest are
+// leaves. Tokenize also produces variation, NAG and suffix annotation leaves,
+// but never TokenEscape.
+const (
+	TokenDatabase TokenType = iota + 100
+	TokenGame
+	TokenTagSection
+	TokenTagPair
+	TokenMovetext
+	TokenMove
+	TokenVariation
+
+	TokenVariationOpen
+	TokenVariationClose
+	TokenNAG
+	TokenEscape
+)
+
+func init() {
+	for t, name := range map[TokenType]string{
+		TokenDatabase:       "TokenDatabase",
+		TokenGame:           "TokenGame",
+		TokenTagSection:     "TokenTagSection",
+		TokenTagPair:        "TokenTagPair",
+		TokenMovetext:       "TokenMovetext",
+		TokenMove:           "TokenMove",
+		TokenVariation:      "TokenVariation",
+		TokenVariationOpen:  "TokenVariationOpen",
+		TokenVariationClose: "TokenVariationClose",
+		TokenNAG:            "TokenNAG",
+		TokenEscape:         "TokenEscape",
+	} {
+		tokenTypeNames[t] = name
+	}
+}
+
+const (
+	ERR_UNEXPECTED           = "Unexpected character"
+	ERR_VARIATION_NOT_CLOSED = "Variation not closed"
+)
+
+// TokenizeTree reads the whole input as a concrete syntax tree. The root is a
+// TokenDatabase whose children are games and the whitespace between them. A
+// TokenGame holds a TokenTagSection of TokenTagPair nodes and a TokenMovetext
+// of move numbers, TokenMove nodes, comments, TokenVariation nodes, NAGs and
+// the result. Whitespace, escape lines and rest-of-line comments are kept as
+// trivia tokens wherever they occur.
+//
+// The tree is lossless: every leaf's Value is its exact source text, so Text
+// of the root returns the input unchanged. Nodes have an empty Value and the
+// Pos of their first child.
+func (l *Lexer) TokenizeTree() (error, Token) {
+	root := Token{Type: TokenDatabase, Pos: l.scanner.Pos()}
+	for {
+		root.Children = append(root.Children, l.readTrivia()...)
+		if isNul(l.scanner.Peek()) {
+			return nil, root
+		}
+		err, game, trivia := l.readGameTree()
+		if len(game.Children) > 0 {
+			root.Children = append(root.Children, game)
+		}
+		root.Children = append(root.Children, trivia...)
+		if err != nil {
+			return err, root
+		}
+	}
+}
+
+// Text returns the source text of the token: its Value for a leaf, or the
+// text of all its children for a node
+func (t Token) Text() string {
+	if len(t.Children) == 0 {
+		return t.Value
+	}
+	var b strings.Builder
+	t.writeText(&b)
+	return b.String()
+}
+
+func (t Token) writeText(b *strings.Builder) {
+	b.WriteString(t.Value)
+	for _, child := range t.Children {
+		child.writeText(b)
+	}
+}
+
+// node returns an interior token of the given type holding children
+func node(tokenType TokenType, children []Token) Token {
+	t := Token{Type: tokenType, Children: children}
+	if len(children) > 0 {
+		t.Pos = children[0].Pos
+	}
+	return t
+}
+
+// leaf returns a token of the given type whose value is the source text from
+// pos up to the scanner's position
+func (l *Lexer) leaf(tokenType TokenType, pos Pos) Token {
+	return Token{
+		Type:  tokenType,
+		Value: l.scanner.stream[pos.Offset:l.scanner.index],
+		Pos:   pos,
+	}
+}
+
+// readGameTree reads a game. The trivia after its last element is returned
+// separately, as it lies between games.
+func (l *Lexer) readGameTree() (error, Token, []Token) {
+	children := []Token{}
+	trivia := []Token{}
+
+	if isLBracket(l.scanner.Peek()) {
+		section := []Token{}
+		for isLBracket(l.scanner.Peek()) {
+			section = append(section, trivia...)
+			err, tagPair := l.readTagPairTree()
+			if len(tagPair.Children) > 0 {
+				section = append(section, tagPair)
+			}
+			if err != nil {
+				return err, node(TokenGame, append(children, node(TokenTagSection, section))), nil
+			}
+			trivia = l.readTrivia()
+		}
+		children = append(children, node(TokenTagSection, section))
+	}
+
+	r := l.scanner.Peek()
+	if isNul(r) || isLBracket(r) {
+		return nil, node(TokenGame, children), trivia
+	}
+	children = append(children, trivia...)
+
+	err, movetext, trivia := l.readElements(TokenMovetext)
+	children = append(children, movetext)
+	return err, node(TokenGame, children), trivia
+}
+
+// Rule: tpair = lb , tname , string , rb ;
+func (l *Lexer) readTagPairTree() (error, Token) {
+	children := []Token{}
+
+	pos := l.scanner.Pos()
+	l.scanner.Next()
+	children = append(children, l.leaf(TokenTagPairOpen, pos))
+	children = append(children, l.readTrivia()...)
+
+	pos = l.scanner.Pos()
+	if l.readTagName() != "" {
+		children = append(children, l.leaf(TokenTagName, pos))
+	}
+	children = append(children, l.readTrivia()...)
+
+	pos = l.scanner.Pos()
+	if err, _ := l.readString(); err != nil {
+		return err, node(TokenTagPair, children)
+	}
+	children = append(children, l.leaf(TokenString, pos))
+	children = append(children, l.readTrivia()...)
+
+	pos = l.scanner.Pos()
+	if !isRBracket(l.scanner.Peek()) {
+		return l.syntaxError(ERR_TAG_PAIR_CLOSE), node(TokenTagPair, children)
+	}
+	l.scanner.Next()
+	children = append(children, l.leaf(TokenTagPairClose, pos))
+
+	return nil, node(TokenTagPair, children)
+}
+
+// readElements reads movetext elements into a node of the given type, either
+// the movetext of a game, which ends after its result, or a variation, which
+// ends before its closing parenthesis. The trivia after the last element is
+// returned separately.
+func (l *Lexer) readElements(tokenType TokenType) (error, Token, []Token) {
+	children := []Token{}
+	for {
+		trivia := l.readTrivia()
+		r := l.scanner.Peek()
+		if isNul(r) || isLBracket(r) || (r == ')' && tokenType == TokenVariation) {
+			return nil, node(tokenType, children), trivia
+		}
+		children = append(children, trivia...)
+
+		pos := l.scanner.Pos()
+		switch {
+		case isCommentOpen(r):
+			err, _, _ := l.readComment()
+			if err != nil {
+				return err, node(tokenType, children), nil
+			}
+			children = append(children, l.leaf(TokenComment, pos))
+
+		case r == '$':
+			l.scanner.Next()
+			if l.readInteger() == "" {
+				return l.syntaxError(ERR_NAG), node(tokenType, children), nil
+			}
+			children = append(children, l.leaf(TokenNAG, pos))
+
+		case r == '!' || r == '?':
+			l.skip(func(r rune) bool { return r == '!' || r == '?' })
+			children = append(children, l.leaf(TokenNAG, pos))
+
+		case r == '(':
+			l.scanner.Next()
+			open := l.leaf(TokenVariationOpen, pos)
+			err, variation, trivia := l.readElements(TokenVariation)
+			variation.Children = append([]Token{open}, variation.Children...)
+			variation.Children = append(variation.Children, trivia...)
+			variation.Pos = pos
+			if err == nil && l.scanner.Peek() != ')' {
+				err = &SyntaxError{Pos: pos, Message: ERR_VARIATION_NOT_CLOSED}
+			}
+			if err != nil {
+				return err, node(tokenType, append(children, variation)), nil
+			}
+			pos = l.scanner.Pos()
+			l.scanner.Next()
+			variation.Children = append(variation.Children, l.leaf(TokenVariationClose, pos))
+			children = append(children, variation)
+
+		case isDigit(r) && !l.atResult():
+			l.readInteger()
+			l.skip(isPeriod)
+			children = append(children, l.leaf(TokenMoveNumber, pos))
+
+		default:
+			err, resultFound, result := l.readResult()
+			if err != nil {
+				return err, node(tokenType, children), nil
+			}
+			if resultFound {
+				children = append(children, result)
+				if tokenType == TokenMovetext {
+					return nil, node(tokenType, children), nil
+				}
+				continue
+			}
+
+			err, moveTokens := l.readMove()
+			if err != nil {
+				return err, node(tokenType, children), nil
+			}
+			if len(moveTokens) == 0 {
+				return l.syntaxError(ERR_UNEXPECTED), node(tokenType, children), nil
+			}
+			children = append(children, node(TokenMove, moveTokens))
+		}
+	}
+}
+
+// readTrivia reads whitespace, escape lines and rest-of-line comments
+func (l *Lexer) readTrivia() []Token {
+	tokens := []Token{}
+	for {
+		pos := l.scanner.Pos()
+		r := l.scanner.Peek()
+		switch {
+		case isWhiteSpace(r):
+			l.readWhitespace()
+			tokens = append(tokens, l.leaf(TokenWhitespace, pos))
+		case r == '%' && pos.Column == 1:
+			l.skip(func(r rune) bool { return !isNul(r) && !isNewLine(r) })
+			tokens = append(tokens, l.leaf(TokenEscape, pos))
+		case r == ';':
+			l.skip(func(r rune) bool { return !isNul(r) && !isNewLine(r) })
+			tokens = append(tokens, l.leaf(TokenComment, pos))
+		default:
+			return tokens
+		}
+	}
+}
+
+// atResult reports whether the digits ahead are a result, such as 1-0,
+// rather than a move number
+func (l *Lexer) atResult() bool {
+	return l.scanner.HasPrefix(literalWhiteWins) || l.scanner.HasPrefix(literalBlackWins) ||
+		l.scanner.HasPrefix("1/")
+}
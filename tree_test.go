@@ -0,0 +1,110 @@
+package pgn_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestTokenizeTreeText(t *testing.T) {
+	data := []struct {
+		name string
+		in   string
+	}{
+		{name: "Game", in: gameA},
+		{name: "Empty", in: ""},
+		{
+			name: "Trivia",
+			in: "% exported by hand\r\n[Event  \"x\" ]\r\n\r\n\t1.e4 ; best by test\n" +
+				"e5!? $14 {a {comment} 2... Nc6 \n\n",
+		},
+		{
+			name: "Variations and games",
+			in: "[White \"a\"]\n\n1. e4 (1. d4 d5 (1... Nf6) 2. c4) 1... e5 *\n\n" +
+				"[White \"b\"]\n1. O-O-O+ exd8=Q# 1-0",
+		},
+	}
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			lexer := pgn.NewLexer(pgn.NewScanner(test.in))
+			err, tree := lexer.TokenizeTree()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := tree.Text(); got != test.in {
+				fmt.Printf("Got: %q\n", got)
+				fmt.Printf("Exp: %q\n", test.in)
+				t.Fatal("Unexpected text")
+			}
+		})
+	}
+}
+
+// shape writes a token tree as nested types, leaving out whitespace
+func shape(t pgn.Token) string {
+	if len(t.Children) == 0 {
+		return t.Value
+	}
+	s := t.Type.String() + "("
+	sep := ""
+	for _, child := range t.Children {
+		if child.Type == pgn.TokenWhitespace {
+			continue
+		}
+		s = s + sep + shape(child)
+		sep = " "
+	}
+	return s + ")"
+}
+
+func TestTokenizeTree(t *testing.T) {
+	in := "[Event \"x\"]\n\n1. e4 $1 (1. d4) e5 {ok} 1-0\n"
+	lexer := pgn.NewLexer(pgn.NewScanner(in))
+	err, tree := lexer.TokenizeTree()
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := "TokenDatabase(TokenGame(" +
+		"TokenTagSection(TokenTagPair([ Event \"x\" ])) " +
+		"TokenMovetext(1. TokenMove(e 4) $1 TokenVariation(( 1. TokenMove(d 4) )) TokenMove(e 5) {ok} 1-0)))"
+	if got := shape(tree); got != exp {
+		fmt.Println("Got:", got)
+		fmt.Println("Exp:", exp)
+		t.Fatal("Unexpected tree")
+	}
+
+	move := tree.Children[0].Children[2].Children[2]
+	if move.Type != pgn.TokenMove || move.Pos != (pgn.Pos{Offset: 16, Line: 3, Column: 4}) {
+		fmt.Println("Got:", move)
+		t.Fatal("Unexpected move token")
+	}
+}
+
+func TestTokenizeTreeError(t *testing.T) {
+	lexer := pgn.NewLexer(pgn.NewScanner("1. e4 (1. d4 d5"))
+	err, tree := lexer.TokenizeTree()
+	if err == nil || err.Error() != pgn.ERR_VARIATION_NOT_CLOSED {
+		fmt.Println("Got:", err)
+		t.Fatal("Expected an unclosed variation")
+	}
+	if tree.Text() != "1. e4 (1. d4 d5" {
+		fmt.Printf("Got: %q\n", tree.Text())
+		t.Fatal("Expected the text read before the error")
+	}
+}
+
+func TestTokenizeTreeBareNAG(t *testing.T) {
+	in := "1. e4 $ e5 *"
+	lexer := pgn.NewLexer(pgn.NewScanner(in))
+	err, _ := lexer.TokenizeTree()
+	if err == nil || err.Error() != pgn.ERR_NAG {
+		fmt.Println("Got:", err)
+		t.Fatal("Expected a NAG error from the tree")
+	}
+	lexer = pgn.NewLexer(pgn.NewScanner(in))
+	if err, _ := lexer.Tokenize(); err == nil || err.Error() != pgn.ERR_NAG {
+		fmt.Println("Got:", err)
+		t.Fatal("Expected the same error from Tokenize")
+	}
+}
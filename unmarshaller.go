@@ -1,17 +1,31 @@
 package pgn
 
 import (
-	"errors"
 	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	ERR_MOVE_NUMBER = "Expected a move to follow the move number"
+	ERR_MOVE        = "Expected a move number or a move"
+	ERR_NO_MOVE     = "Expected a move for the annotation to follow"
 )
 
 type Game struct {
 	TagPairs []TagPair
+	// Comment is the text of any comment before the first move
+	Comment  string
 	Movetext []Movetext
+	// Termination is the game termination marker that ends the movetext, or
+	// "" if there is none
+	Termination Result
 }
 
 type TagPair struct {
 	Name, Value string
+	// Pos is where the tag name starts in the input
+	Pos Pos
 }
 
 type Movetext struct {
@@ -20,8 +34,31 @@ type Movetext struct {
 	Black Move
 }
 
+// Variation is a recursive annotation variation: a line of play that could
+// have been played instead of the move it follows, written in parentheses
+type Variation struct {
+	// Comment is the text of any comment before the variation's first move
+	Comment  string
+	Movetext []Movetext
+}
+
+// Result is a game result as written in the Result tag and as the game
+// termination marker
+type Result string
+
+const (
+	ResultWhiteWins Result = literalWhiteWins
+	ResultBlackWins Result = literalBlackWins
+	ResultDraw      Result = literalDraw
+	// ResultUnknown marks a game in progress, abandoned, or with an unknown
+	// result
+	ResultUnknown Result = literalUnknownResult
+)
+
 type File string
 type Rank int
+type Piece string
+type Castle string
 
 const (
 	FileA File = "a"
@@ -45,9 +82,81 @@ const (
 	Rank8 Rank = 8
 )
 
+const (
+	PiecePawn   Piece = "P"
+	PieceKnight Piece = "N"
+	PieceBishop Piece = "B"
+	PieceRook   Piece = "R"
+	PieceQueen  Piece = "Q"
+	PieceKing   Piece = "K"
+)
+
+const (
+	CastleKingside  Castle = literalCastleKingside
+	CastleQueenside Castle = literalCastleQueenside
+)
+
+// Move is one ply in standard algebraic notation (SAN). File and Rank are the
+// destination square; FromFile and FromRank are set only when the SAN
+// disambiguates the originating square or names the file of a capturing pawn.
+// An empty Piece is a pawn. A castling move sets only Castle.
 type Move struct {
-	File File
-	Rank Rank
+	Piece     Piece
+	FromFile  File
+	FromRank  Rank
+	Capture   bool
+	File      File
+	Rank      Rank
+	Castle    Castle
+	Promotion Piece
+	Check     bool
+	Checkmate bool
+
+	// Comment is the text of the comments following the move, without their
+	// embedded commands
+	Comment string
+	// Clock is the time left on the player's clock after the move, from a
+	// [%clk] command
+	Clock *time.Duration
+	// ElapsedTime is the time the player spent on the move, from an [%emt]
+	// command
+	ElapsedTime *time.Duration
+	// Eval is the engine evaluation after the move, from an [%eval] command
+	Eval *Eval
+	// Squares are the highlighted squares from [%csl] commands
+	Squares []SquareAnnotation
+	// Arrows are the arrows from [%cal] commands
+	Arrows []ArrowAnnotation
+	// Commands holds the other embedded commands, in the order they appeared
+	Commands []Command
+	// NAGs are the numeric annotation glyphs following the move, such as 1
+	// for $1 or for the suffix annotation !
+	NAGs []int
+	// Variations are the alternatives to the move, in the order written
+	Variations []Variation
+
+	// Pos is where the move starts in the input
+	Pos Pos
+}
+
+// IsZero reports whether m holds no move, as for Black when the game ends
+// after White's move
+func (m Move) IsZero() bool {
+	return m.File == "" && m.Castle == ""
+}
+
+// Plies returns the number of half moves in the game
+func (g *Game) Plies() int {
+	plies := 0
+	for _, mt := range g.Movetext {
+		if !mt.White.IsZero() {
+			plies++
+		}
+		if !mt.Black.IsZero() {
+			plies++
+		}
+	}
+	return plies
 }
 
 type PGN struct {
@@ -72,67 +181,299 @@ func Unmarshal(in string, unmarshalled *PGN) error {
 
 	u := unmarshaller{tokens: tokens}
 
-	game := Game{}
+	for {
+		game := Game{}
+
+		ok := true
+		for ok {
+			tagPair := u.readTagPair()
+			if tagPair != nil {
+				game.TagPairs = append(game.TagPairs, *tagPair)
+			} else {
+				ok = false
+			}
+		}
+
+		// move text
+		err = u.readMovetext(&game)
+		if err != nil {
+			return err
+		}
+
+		unmarshalled.Games = append(unmarshalled.Games, game)
 
-	ok := true
-	for ok {
-		tagPair := u.readTagPair()
-		if tagPair != nil {
-			game.TagPairs = append(game.TagPairs, *tagPair)
+		token := u.peek()
+		if token == nil || token.Type == TokenEOF {
+			return nil
+		}
+	}
+}
+
+// readMovetext reads the movetext of a game up to and including its
+// termination marker
+func (u *unmarshaller) readMovetext(game *Game) error {
+	if err := u.readMoves(&game.Comment, &game.Movetext); err != nil {
+		return err
+	}
+
+	token := u.peek()
+	if token == nil || !isResultToken(token) {
+		return nil
+	}
+	u.next()
+	game.Termination = Result(token.Value)
+	for _, comment := range u.readComments() {
+		if m := lastMove(game.Movetext); m != nil {
+			m.setComment(comment)
 		} else {
-			ok = false
+			game.Comment = joinComment(game.Comment, comment)
 		}
 	}
+	return nil
+}
 
-	// move text
-	ok = true
-	for ok {
+// readMoves reads move numbers and moves, with the comments, NAGs and
+// variations that annotate them, up to a termination marker, the end of a
+// variation or the next game. Comments before the first move are added to
+// comment.
+func (u *unmarshaller) readMoves(comment *string, movetext *[]Movetext) error {
+	number, black := 0, false
+	for {
 		token := u.peek()
-		if token != nil && token.Type == TokenMoveNumber {
-			u.next()
+		if token == nil || token.Type == TokenEOF || token.Type == TokenTagName ||
+			token.Type == TokenVariationClose || isResultToken(token) {
+			return nil
+		}
 
-			// convert value to int
-			i, err := strconv.Atoi(token.Value)
-			if err != nil {
-				return err
+		switch {
+		case token.Type == TokenComment:
+			u.next()
+			if m := lastMove(*movetext); m != nil {
+				m.setComment(token.Value)
+			} else {
+				*comment = joinComment(*comment, token.Value)
 			}
 
-			// create movetext instance
-			m := Movetext{
-				Num: i,
+		case token.Type == TokenNAG:
+			u.next()
+			nag, ok := parseNAG(token.Value)
+			if !ok {
+				return &SyntaxError{Pos: token.Pos, Message: ERR_NAG}
+			}
+			m := lastMove(*movetext)
+			if m == nil {
+				return &SyntaxError{Pos: token.Pos, Message: ERR_NO_MOVE}
 			}
+			m.NAGs = append(m.NAGs, nag)
 
-			// parse white move
-			token = u.peek()
-			if token == nil {
-				return errors.New("white movetext token is nil")
+		case token.Type == TokenVariationOpen:
+			u.next()
+			m := lastMove(*movetext)
+			if m == nil {
+				return &SyntaxError{Pos: token.Pos, Message: ERR_NO_MOVE}
+			}
+			variation := Variation{}
+			if err := u.readMoves(&variation.Comment, &variation.Movetext); err != nil {
+				return err
+			}
+			end := u.next()
+			if end == nil || end.Type == TokenEOF {
+				return &SyntaxError{Pos: token.Pos, Message: ERR_VARIATION_NOT_CLOSED}
 			}
-			if token.Type == TokenFile {
-				m.White = Move{File: File(token.Value)}
+			if end.Type != TokenVariationClose {
+				// a termination marker inside a variation
+				return &SyntaxError{Pos: end.Pos, Message: ERR_MOVE}
 			}
+			m.Variations = append(m.Variations, variation)
 
+		case token.Type == TokenMoveNumber:
 			u.next()
-
-			// parse black move
-			token = u.peek()
-			if token == nil {
-				return errors.New("black movetext token is nil")
+			value := strings.TrimSuffix(token.Value, literalEllipsis)
+			i, err := strconv.Atoi(value)
+			if err != nil {
+				return err
 			}
-			if token.Type == TokenFile {
-				m.Black = Move{File: File(token.Value)}
+			number, black = i, value != token.Value
+
+			// a comment between the move number and the move belongs with
+			// the comments before it
+			for _, c := range u.readComments() {
+				if m := lastMove(*movetext); m != nil {
+					m.setComment(c)
+				} else {
+					*comment = joinComment(*comment, c)
+				}
 			}
 
-			u.next()
+			if !isMoveToken(u.peek()) {
+				return &SyntaxError{Pos: token.Pos, Message: ERR_MOVE_NUMBER}
+			}
 
-			game.Movetext = append(game.Movetext, m)
-		} else {
-			ok = false
+		case isMoveToken(token):
+			placeMove(movetext, u.readMove(), number, black)
+			number, black = 0, false
+
+		default:
+			return &SyntaxError{Pos: token.Pos, Message: ERR_MOVE}
 		}
 	}
+}
 
-	unmarshalled.Games = append(unmarshalled.Games, game)
+// placeMove adds a move to the movetext. number is the move number written
+// before the move, or 0 if there was none, and black is whether it was
+// written with an ellipsis, as in 3... Nf6, which puts the move in Black's
+// place even when it is the first move of a game or variation.
+func placeMove(movetext *[]Movetext, move Move, number int, black bool) {
+	mt := *movetext
+	last := len(mt) - 1
+	switch {
+	case number > 0 && last >= 0 && mt[last].Num == number && mt[last].Black.IsZero():
+		// a move number may repeat before Black's move
+		mt[last].Black = move
+	case number > 0 && black:
+		mt = append(mt, Movetext{Num: number, Black: move})
+	case number > 0:
+		mt = append(mt, Movetext{Num: number, White: move})
+	case last < 0:
+		// movetext without move numbers
+		mt = append(mt, Movetext{Num: 1, White: move})
+	case mt[last].Black.IsZero():
+		mt[last].Black = move
+	default:
+		mt = append(mt, Movetext{Num: mt[last].Num + 1, White: move})
+	}
+	*movetext = mt
+}
 
-	return nil
+// lastMove returns the last move of the movetext, or nil if there is none
+func lastMove(movetext []Movetext) *Move {
+	last := len(movetext) - 1
+	switch {
+	case last < 0:
+		return nil
+	case movetext[last].Black.IsZero():
+		return &movetext[last].White
+	default:
+		return &movetext[last].Black
+	}
+}
+
+// suffixNAGs are the NAGs that the suffix annotations stand for
+var suffixNAGs = map[string]int{"!": 1, "?": 2, "!!": 3, "??": 4, "!?": 5, "?!": 6}
+
+// parseNAG parses a NAG such as $14 or a suffix annotation such as !?
+func parseNAG(s string) (int, bool) {
+	if strings.HasPrefix(s, "$") {
+		n, err := strconv.Atoi(s[1:])
+		return n, err == nil && n <= 255
+	}
+	n, ok := suffixNAGs[s]
+	return n, ok
+}
+
+// readMove reads the tokens of a single move. A move is written without
+// whitespace, so its tokens are the run of move tokens that touch each other.
+// The lexer emits the square in the order it is written, so the last file and
+// rank are the destination and any before them are the disambiguation.
+func (u *unmarshaller) readMove() Move {
+	move := Move{}
+	files := []File{}
+	ranks := []Rank{}
+
+	var prev *Token
+	for {
+		token := u.peek()
+		if !isMoveToken(token) {
+			break
+		}
+		if prev != nil && token.Pos.Offset != prev.Pos.Offset+len(prev.Value) {
+			break
+		}
+		u.next()
+		if prev == nil {
+			move.Pos = token.Pos
+		}
+		prev = token
+
+		switch token.Type {
+		case TokenCastleKingside:
+			move.Castle = CastleKingside
+		case TokenCastleQueenside:
+			move.Castle = CastleQueenside
+		case TokenPiece:
+			move.Piece = Piece(token.Value)
+		case TokenCapture:
+			move.Capture = true
+		case TokenFile:
+			files = append(files, File(token.Value))
+		case TokenRank:
+			r, _ := strconv.Atoi(token.Value)
+			ranks = append(ranks, Rank(r))
+		case TokenPromotionPiece:
+			move.Promotion = Piece(token.Value)
+		case TokenCheck:
+			move.Check = true
+		case TokenCheckmate:
+			move.Checkmate = true
+		}
+	}
+
+	if len(files) > 0 {
+		move.File = files[len(files)-1]
+	}
+	if len(ranks) > 0 {
+		move.Rank = ranks[len(ranks)-1]
+	}
+	if len(files) > 1 {
+		move.FromFile = files[0]
+	}
+	if len(ranks) > 1 {
+		move.FromRank = ranks[0]
+	}
+	return move
+}
+
+func isResultToken(t *Token) bool {
+	if t == nil {
+		return false
+	}
+	switch t.Type {
+	case TokenWhiteWins, TokenBlackWins, TokenDraw, TokenUnknownResult:
+		return true
+	}
+	return false
+}
+
+func isMoveToken(t *Token) bool {
+	if t == nil {
+		return false
+	}
+	switch t.Type {
+	case TokenPiece, TokenFile, TokenRank, TokenCapture, TokenCastleKingside,
+		TokenCastleQueenside, TokenPromotionIndicator, TokenPromotionPiece,
+		TokenCheck, TokenCheckmate:
+		return true
+	}
+	return false
+}
+
+func (u *unmarshaller) readComments() []string {
+	comments := []string{}
+	for {
+		token := u.peek()
+		if token == nil || token.Type != TokenComment {
+			return comments
+		}
+		u.next()
+		comments = append(comments, token.Value)
+	}
+}
+
+func joinComment(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + " " + b
 }
 
 func (u *unmarshaller) readTagPair() *TagPair {
@@ -142,6 +483,7 @@ func (u *unmarshaller) readTagPair() *TagPair {
 	if token != nil && token.Type == TokenTagName {
 		u.next()
 		tagPair.Name = token.Value
+		tagPair.Pos = token.Pos
 		token = u.peek()
 		if token != nil && token.Type == TokenTagValue {
 			u.next()
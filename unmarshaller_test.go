@@ -2,7 +2,9 @@ package pgn_test
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/miketmoore/pgn"
 )
@@ -101,3 +103,162 @@ func TestUnmarshal(t *testing.T) {
 		})
 	}
 }
+
+func TestUnmarshalMoves(t *testing.T) {
+	var unmarshalled pgn.PGN
+	err := pgn.Unmarshal("1. e4 e5 2. Nf3 Nbd7 3. R1e2 exd5 4. O-O-O+ Qh4xe1# 5. e8=Q+", &unmarshalled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	exp := []pgn.Movetext{
+		pgn.Movetext{
+			Num:   1,
+			White: pgn.Move{File: pgn.FileE, Rank: pgn.Rank4},
+			Black: pgn.Move{File: pgn.FileE, Rank: pgn.Rank5},
+		},
+		pgn.Movetext{
+			Num:   2,
+			White: pgn.Move{Piece: pgn.PieceKnight, File: pgn.FileF, Rank: pgn.Rank3},
+			Black: pgn.Move{Piece: pgn.PieceKnight, FromFile: pgn.FileB, File: pgn.FileD, Rank: pgn.Rank7},
+		},
+		pgn.Movetext{
+			Num:   3,
+			White: pgn.Move{Piece: pgn.PieceRook, FromRank: pgn.Rank1, File: pgn.FileE, Rank: pgn.Rank2},
+			Black: pgn.Move{FromFile: pgn.FileE, Capture: true, File: pgn.FileD, Rank: pgn.Rank5},
+		},
+		pgn.Movetext{
+			Num:   4,
+			White: pgn.Move{Castle: pgn.CastleQueenside, Check: true},
+			Black: pgn.Move{Piece: pgn.PieceQueen, FromFile: pgn.FileH, FromRank: pgn.Rank4, Capture: true, File: pgn.FileE, Rank: pgn.Rank1, Checkmate: true},
+		},
+		pgn.Movetext{
+			Num:   5,
+			White: pgn.Move{File: pgn.FileE, Rank: pgn.Rank8, Promotion: pgn.PieceQueen, Check: true},
+		},
+	}
+	got := unmarshalled.Games[0].Movetext
+	for i := range got {
+		got[i].White.Pos = pgn.Pos{}
+		got[i].Black.Pos = pgn.Pos{}
+	}
+	if !reflect.DeepEqual(got, exp) {
+		fmt.Println("Got:", got)
+		fmt.Println("Exp:", exp)
+		t.Fatal("Unexpected movetext")
+	}
+}
+
+func TestUnmarshalCommentCommands(t *testing.T) {
+	in := "1. e4 {[%eval 0.17] [%clk 0:03:00]} 1... e5 {Solid. [%clk 0:02:58.5] [%emt 0:00:02]}\n" +
+		"2. Qh5 {[%eval #-3,20] [%foo bar]}"
+	var unmarshalled pgn.PGN
+	err := pgn.Unmarshal(in, &unmarshalled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	movetext := unmarshalled.Games[0].Movetext
+	if len(movetext) != 2 {
+		fmt.Println("Got:", movetext)
+		t.Fatal("Unexpected total movetext")
+	}
+
+	white := movetext[0].White
+	if white.Clock == nil || *white.Clock != 3*time.Minute {
+		t.Fatal("Unexpected white clock")
+	}
+	if white.Eval == nil || *white.Eval != (pgn.Eval{Centipawns: 17}) {
+		t.Fatal("Unexpected white eval")
+	}
+
+	black := movetext[0].Black
+	if black.Comment != "Solid." {
+		fmt.Println("Got:", black.Comment)
+		t.Fatal("Unexpected black comment")
+	}
+	if black.Clock == nil || *black.Clock != 178*time.Second+500*time.Millisecond {
+		t.Fatal("Unexpected black clock")
+	}
+	if black.ElapsedTime == nil || *black.ElapsedTime != 2*time.Second {
+		t.Fatal("Unexpected black elapsed time")
+	}
+
+	queen := movetext[1].White
+	if queen.Eval == nil || *queen.Eval != (pgn.Eval{Mate: -3, Depth: 20}) {
+		t.Fatal("Unexpected mate eval")
+	}
+	if !reflect.DeepEqual(queen.Commands, []pgn.Command{pgn.Command{Name: "foo", Value: "bar"}}) {
+		fmt.Println("Got:", queen.Commands)
+		t.Fatal("Expected unknown command to be kept")
+	}
+}
+
+func TestUnmarshalMultipleGames(t *testing.T) {
+	in := "[Event \"A\"]\n[Result \"1-0\"]\n\n1. e4 e5 2. Qh5 1-0\n\n" +
+		"[Event \"B\"]\n[Result \"0-1\"]\n\n1. f3 e5 2. g4 Qh4# 0-1\n\n" +
+		"[Event \"C\"]\n\n*\n"
+	var unmarshalled pgn.PGN
+	err := pgn.Unmarshal(in, &unmarshalled)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(unmarshalled.Games) != 3 {
+		fmt.Println("Got:", unmarshalled.Games)
+		t.Fatal("Unexpected total games")
+	}
+	exp := []struct {
+		event       string
+		plies       int
+		termination pgn.Result
+	}{
+		{"A", 3, pgn.ResultWhiteWins},
+		{"B", 4, pgn.ResultBlackWins},
+		{"C", 0, pgn.ResultUnknown},
+	}
+	for i, e := range exp {
+		game := unmarshalled.Games[i]
+		if game.Event() != e.event || game.Plies() != e.plies || game.Termination != e.termination {
+			fmt.Println("Got:", game.Event(), game.Plies(), game.Termination)
+			fmt.Println("Exp:", e)
+			t.Fatal("Unexpected game")
+		}
+	}
+}
+
+func TestUnmarshalAnnotations(t *testing.T) {
+	in := "1. e4 $1 e5 2. Nf3 (2. Nc3 {Vienna} Nf6 (2... Nc6 3. f4!?)) Nc6 ; the main line\n3. Bb5 1-0"
+	var unmarshalled pgn.PGN
+	if err := pgn.Unmarshal(in, &unmarshalled); err != nil {
+		t.Fatal(err)
+	}
+	game := unmarshalled.Games[0]
+	if game.Plies() != 5 || game.Termination != pgn.ResultWhiteWins {
+		fmt.Println("Got:", game.Plies(), game.Termination)
+		t.Fatal("Unexpected main line")
+	}
+	if got := game.Movetext[0].White.NAGs; !reflect.DeepEqual(got, []int{1}) {
+		fmt.Println("Got:", got)
+		t.Fatal("Unexpected NAGs")
+	}
+	if got := game.Movetext[1].Black.Comment; got != "the main line" {
+		fmt.Println("Got:", got)
+		t.Fatal("Unexpected rest-of-line comment")
+	}
+
+	variations := game.Movetext[1].White.Variations
+	if len(variations) != 1 {
+		fmt.Println("Got:", variations)
+		t.Fatal("Unexpected total variations")
+	}
+	vienna := variations[0].Movetext
+	if len(vienna) != 1 || vienna[0].White.String() != "Nc3" || vienna[0].White.Comment != "Vienna" ||
+		vienna[0].Black.String() != "Nf6" {
+		fmt.Println("Got:", vienna)
+		t.Fatal("Unexpected variation")
+	}
+	nested := vienna[0].Black.Variations
+	if len(nested) != 1 || len(nested[0].Movetext) != 2 || !nested[0].Movetext[0].White.IsZero() ||
+		nested[0].Movetext[0].Black.String() != "Nc6" || !reflect.DeepEqual(nested[0].Movetext[1].White.NAGs, []int{5}) {
+		fmt.Println("Got:", nested)
+		t.Fatal("Unexpected nested variation")
+	}
+}
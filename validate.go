@@ -0,0 +1,230 @@
+package pgn
+
+import (
+	"fmt"
+	"strconv"
+)
+
+const TagPlyCount = "PlyCount"
+
+// Severity is how serious an Issue is
+type Severity int
+
+const (
+	// SeverityWarning marks departures from the standard's conventions that
+	// readers usually cope with
+	SeverityWarning Severity = iota
+	// SeverityError marks data that breaks the standard or contradicts
+	// itself
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	}
+	return "unknown"
+}
+
+// Issue is a problem found by validation. Tag names the tag pair at fault,
+// if there is one, and Pos is where the problem is in the input when it is
+// known.
+type Issue struct {
+	Severity Severity
+	Tag      string
+	Message  string
+	Pos      Pos
+}
+
+func (i Issue) String() string {
+	if i.Tag == "" {
+		return i.Severity.String() + ": " + i.Message
+	}
+	return i.Severity.String() + ": " + i.Tag + ": " + i.Message
+}
+
+// ValidateTags checks the tag section of a game against the PGN standard:
+// the Seven Tag Roster is present and comes first in its standard order, tag
+// names are legal and not repeated, the Result tag agrees with the termination
+// marker, PlyCount agrees with the movetext, and tags with a defined format,
+// such as Date, Round and TimeControl, follow it.
+func ValidateTags(g *Game) []Issue {
+	issues := []Issue{}
+	issues = append(issues, validateRoster(g)...)
+	issues = append(issues, validateTagNames(g)...)
+	issues = append(issues, validateResult(g)...)
+	issues = append(issues, validatePlyCount(g)...)
+	issues = append(issues, validateTagFormats(g)...)
+	for i := range issues {
+		if issues[i].Pos == (Pos{}) {
+			issues[i].Pos = g.tagPos(issues[i].Tag)
+		}
+	}
+	return issues
+}
+
+// tagPos returns the position of the first tag pair with the given name
+func (g *Game) tagPos(name string) Pos {
+	for _, tp := range g.TagPairs {
+		if tp.Name == name {
+			return tp.Pos
+		}
+	}
+	return Pos{}
+}
+
+func validateRoster(g *Game) []Issue {
+	issues := []Issue{}
+
+	missing := false
+	for _, name := range SevenTagRoster {
+		if _, ok := g.Tag(name); !ok {
+			missing = true
+			issues = append(issues, Issue{
+				Severity: SeverityError,
+				Tag:      name,
+				Message:  "missing tag from the Seven Tag Roster",
+			})
+		}
+	}
+	if missing {
+		return issues
+	}
+
+	for i, name := range SevenTagRoster {
+		if i >= len(g.TagPairs) || g.TagPairs[i].Name != name {
+			issues = append(issues, Issue{
+				Severity: SeverityWarning,
+				Tag:      name,
+				Message:  "the Seven Tag Roster should come first, in the order " + fmt.Sprint(SevenTagRoster),
+			})
+			break
+		}
+	}
+	return issues
+}
+
+func validateTagNames(g *Game) []Issue {
+	issues := []Issue{}
+	for _, tp := range g.TagPairs {
+		if tp.Name == "" {
+			issues = append(issues, Issue{Severity: SeverityError, Message: "empty tag name"})
+			continue
+		}
+		legal := true
+		for _, r := range tp.Name {
+			if !isLetter(r) && !isDigit(r) && !isUnderscore(r) {
+				legal = false
+			}
+		}
+		if !legal {
+			issues = append(issues, Issue{
+				Severity: SeverityError,
+				Tag:      tp.Name,
+				Message:  "tag names may only hold letters, digits and underscores",
+			})
+		} else if first := rune(tp.Name[0]); first < 'A' || first > 'Z' {
+			issues = append(issues, Issue{
+				Severity: SeverityWarning,
+				Tag:      tp.Name,
+				Message:  "tag names should begin with an upper case letter",
+			})
+		}
+	}
+	for _, name := range g.DuplicateTags() {
+		issues = append(issues, Issue{
+			Severity: SeverityError,
+			Tag:      name,
+			Message:  "duplicate tag",
+		})
+	}
+	return issues
+}
+
+func validateResult(g *Game) []Issue {
+	value, ok := g.Tag(TagResult)
+	if !ok {
+		return nil
+	}
+	switch Result(value) {
+	case ResultWhiteWins, ResultBlackWins, ResultDraw, ResultUnknown:
+	default:
+		return []Issue{{
+			Severity: SeverityError,
+			Tag:      TagResult,
+			Message:  fmt.Sprintf("result %q is not one of 1-0, 0-1, 1/2-1/2 or *", value),
+		}}
+	}
+	if g.Termination == "" {
+		return []Issue{{
+			Severity: SeverityWarning,
+			Tag:      TagResult,
+			Message:  "movetext has no game termination marker",
+		}}
+	}
+	if Result(value) != g.Termination {
+		return []Issue{{
+			Severity: SeverityError,
+			Tag:      TagResult,
+			Message:  fmt.Sprintf("result %s does not agree with the game termination marker %s", value, g.Termination),
+		}}
+	}
+	return nil
+}
+
+func validatePlyCount(g *Game) []Issue {
+	value, ok := g.Tag(TagPlyCount)
+	if !ok {
+		return nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return []Issue{{
+			Severity: SeverityError,
+			Tag:      TagPlyCount,
+			Message:  fmt.Sprintf("ply count %q is not a non-negative integer", value),
+		}}
+	}
+	if n != g.Plies() {
+		return []Issue{{
+			Severity: SeverityError,
+			Tag:      TagPlyCount,
+			Message:  fmt.Sprintf("ply count %d does not agree with the %d plies in the movetext", n, g.Plies()),
+		}}
+	}
+	return nil
+}
+
+func validateTagFormats(g *Game) []Issue {
+	issues := []Issue{}
+	for _, tp := range g.TagPairs {
+		var err error
+		switch tp.Name {
+		case TagDate, TagEventDate, TagUTCDate:
+			_, err = ParseDate(tp.Value)
+		case TagRound:
+			_, err = ParseRound(tp.Value)
+		case TagTimeControl:
+			_, err = ParseTimeControl(tp.Value)
+		case TagWhiteElo, TagBlackElo, TagWhiteUSCF, TagBlackUSCF:
+			_, _, err = ParseRating(tp.Value)
+		case TagWhiteRatingDiff, TagBlackRatingDiff:
+			_, _, err = ParseRatingDiff(tp.Value)
+		case TagWhiteTitle, TagBlackTitle:
+			_, _, err = ParseTitle(tp.Value)
+		case TagWhiteFideId, TagBlackFideId:
+			_, _, err = ParseFideId(tp.Value)
+		}
+		if err != nil {
+			issues = append(issues, Issue{
+				Severity: SeverityError,
+				Tag:      tp.Name,
+				Message:  err.Error(),
+			})
+		}
+	}
+	return issues
+}
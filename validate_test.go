@@ -0,0 +1,88 @@
+package pgn_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestValidateTags(t *testing.T) {
+	roster := "[Event \"F/S Return Match\"]\n" +
+		"[Site \"Belgrade, Serbia JUG\"]\n" +
+		"[Date \"1992.11.04\"]\n" +
+		"[Round \"29\"]\n" +
+		"[White \"Fischer, Robert J.\"]\n" +
+		"[Black \"Spassky, Boris V.\"]\n"
+
+	data := []struct {
+		name string
+		in   string
+		out  []pgn.Issue
+	}{
+		{
+			name: "Valid",
+			in:   roster + "[Result \"1-0\"]\n[PlyCount \"3\"]\n\n1. e4 e5 2. Nf3 1-0",
+			out:  []pgn.Issue{},
+		},
+		{
+			name: "Missing roster tag",
+			in:   "[Event \"?\"]\n\n*",
+			out: []pgn.Issue{
+				pgn.Issue{Severity: pgn.SeverityError, Tag: "Site", Message: "missing tag from the Seven Tag Roster"},
+				pgn.Issue{Severity: pgn.SeverityError, Tag: "Date", Message: "missing tag from the Seven Tag Roster"},
+				pgn.Issue{Severity: pgn.SeverityError, Tag: "Round", Message: "missing tag from the Seven Tag Roster"},
+				pgn.Issue{Severity: pgn.SeverityError, Tag: "White", Message: "missing tag from the Seven Tag Roster"},
+				pgn.Issue{Severity: pgn.SeverityError, Tag: "Black", Message: "missing tag from the Seven Tag Roster"},
+				pgn.Issue{Severity: pgn.SeverityError, Tag: "Result", Message: "missing tag from the Seven Tag Roster"},
+			},
+		},
+		{
+			name: "Roster out of order",
+			in:   "[Result \"*\"]\n" + roster + "\n*",
+			out: []pgn.Issue{
+				pgn.Issue{Severity: pgn.SeverityWarning, Tag: "Event", Message: "the Seven Tag Roster should come first, in the order [Event Site Date Round White Black Result]"},
+			},
+		},
+		{
+			name: "Names, duplicates and formats",
+			in: roster + "[Result \"*\"]\n[white \"x\"]\n[Round \"R1\"]\n" +
+				"[Date \"1992-11-04\"]\n[TimeControl \"5m\"]\n\n*",
+			out: []pgn.Issue{
+				pgn.Issue{Severity: pgn.SeverityWarning, Tag: "white", Message: "tag names should begin with an upper case letter"},
+				pgn.Issue{Severity: pgn.SeverityError, Tag: "Round", Message: "duplicate tag"},
+				pgn.Issue{Severity: pgn.SeverityError, Tag: "Date", Message: "duplicate tag"},
+				pgn.Issue{Severity: pgn.SeverityError, Tag: "Round", Message: pgn.ERR_ROUND_FORMAT},
+				pgn.Issue{Severity: pgn.SeverityError, Tag: "Date", Message: pgn.ERR_DATE_FORMAT},
+				pgn.Issue{Severity: pgn.SeverityError, Tag: "TimeControl", Message: pgn.ERR_TIME_CONTROL_FORMAT},
+			},
+		},
+		{
+			name: "Result and PlyCount disagree with movetext",
+			in:   roster + "[Result \"1-0\"]\n[PlyCount \"40\"]\n\n1. e4 e5 1/2-1/2",
+			out: []pgn.Issue{
+				pgn.Issue{Severity: pgn.SeverityError, Tag: "Result", Message: "result 1-0 does not agree with the game termination marker 1/2-1/2"},
+				pgn.Issue{Severity: pgn.SeverityError, Tag: "PlyCount", Message: "ply count 40 does not agree with the 2 plies in the movetext"},
+			},
+		},
+	}
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			var unmarshalled pgn.PGN
+			err := pgn.Unmarshal(test.in, &unmarshalled)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := pgn.ValidateTags(&unmarshalled.Games[0])
+			for i := range got {
+				got[i].Pos = pgn.Pos{}
+			}
+			if !reflect.DeepEqual(got, test.out) {
+				fmt.Println("Got:", got)
+				fmt.Println("Exp:", test.out)
+				t.Fatal("Unexpected issues")
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package pgn
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ExportLineLength is the longest movetext line written in export format
+const ExportLineLength = 79
+
+// Marshal writes the games in PGN. Each game is written as its tag pairs, one
+// per line, a blank line, then the movetext and the termination marker. When
+// the game has no termination marker it is taken from the Result tag, or is
+// "*" if there is none. Games are separated by a blank line.
+func Marshal(in PGN) string {
+	games := make([]string, len(in.Games))
+	for i, game := range in.Games {
+		games[i] = game.String()
+	}
+	return strings.Join(games, "\n")
+}
+
+// String writes the game in PGN
+func (g Game) String() string {
+	var b strings.Builder
+
+	for _, tp := range g.TagPairs {
+		b.WriteString("[" + tp.Name + " " + quoteString(tp.Value) + "]\n")
+	}
+	if len(g.TagPairs) > 0 {
+		b.WriteString("\n")
+	}
+
+	b.WriteString(strings.Join(g.movetextElements(), " "))
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// MarshalExport writes the games in the PGN export format, the canonical form
+// the standard asks programs to write. See Game.Export.
+func MarshalExport(in PGN) string {
+	games := make([]string, len(in.Games))
+	for i, game := range in.Games {
+		games[i] = game.Export()
+	}
+	return strings.Join(games, "\n")
+}
+
+// Export writes the game in the PGN export format: the Seven Tag Roster in its
+// standard order followed by the other tags in ASCII order, a blank line, then
+// the movetext with single spaces between its elements, wrapped so no line is
+// longer than ExportLineLength. Comments are wrapped with the movetext, so
+// their whitespace is not kept.
+func (g Game) Export() string {
+	var b strings.Builder
+
+	for _, tp := range g.exportTagPairs() {
+		b.WriteString("[" + tp.Name + " " + quoteString(tp.Value) + "]\n")
+	}
+	if len(g.TagPairs) > 0 {
+		b.WriteString("\n")
+	}
+
+	line := 0
+	for _, word := range exportWords(g.movetextElements()) {
+		// a line starting with % is an escape, so never break before one
+		width := utf8.RuneCountInString(word)
+		if line > 0 && line+1+width > ExportLineLength && word[0] != '%' {
+			b.WriteString("\n")
+			line = 0
+		}
+		if line > 0 {
+			b.WriteString(" ")
+			line++
+		}
+		b.WriteString(word)
+		line += width
+	}
+	b.WriteString("\n")
+
+	return b.String()
+}
+
+// exportWords splits the movetext elements into the words lines are wrapped
+// between. A move number is kept with the word after it, so a line never ends
+// with a move number whose move is on the next line.
+func exportWords(elements []string) []string {
+	words := []string{}
+	number := ""
+	for _, element := range elements {
+		for _, word := range strings.Fields(element) {
+			if number != "" {
+				word = number + " " + word
+				number = ""
+			}
+			if isExportMoveNumber(word) {
+				number = word
+				continue
+			}
+			words = append(words, word)
+		}
+	}
+	if number != "" {
+		words = append(words, number)
+	}
+	return words
+}
+
+// isExportMoveNumber reports whether a word is a move number such as "12." or
+// "12...", possibly opening a variation
+func isExportMoveNumber(word string) bool {
+	word = strings.TrimLeft(word, "(")
+	digits := strings.TrimRight(word, ".")
+	if digits == "" || digits == word {
+		return false
+	}
+	for _, r := range digits {
+		if !isDigit(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// exportTagPairs returns the tag pairs in export order. Repeated tags keep
+// their order relative to each other.
+func (g Game) exportTagPairs() []TagPair {
+	rank := func(name string) int {
+		for i, roster := range SevenTagRoster {
+			if name == roster {
+				return i
+			}
+		}
+		return len(SevenTagRoster)
+	}
+	tagPairs := append([]TagPair{}, g.TagPairs...)
+	sort.SliceStable(tagPairs, func(i, j int) bool {
+		ri, rj := rank(tagPairs[i].Name), rank(tagPairs[j].Name)
+		if ri != rj {
+			return ri < rj
+		}
+		return ri == len(SevenTagRoster) && tagPairs[i].Name < tagPairs[j].Name
+	})
+	return tagPairs
+}
+
+// movetextElements returns the movetext as a list of move numbers, moves,
+// NAGs, comments, variations and the termination marker
+func (g Game) movetextElements() []string {
+	elements := movesElements(g.Comment, g.Movetext)
+	result := string(g.Termination)
+	if result == "" {
+		result = g.Result()
+	}
+	if result == "" {
+		result = string(ResultUnknown)
+	}
+	return append(elements, result)
+}
+
+// movesElements returns the elements of a line of moves, starting with the
+// comment before its first move. The move number is repeated before Black's
+// move when a comment or variation comes between it and White's move.
+func movesElements(comment string, movetext []Movetext) []string {
+	elements := []string{}
+	if comment != "" {
+		elements = append(elements, "{"+comment+"}")
+	}
+	for _, mt := range movetext {
+		num := strconv.Itoa(mt.Num)
+		if !mt.White.IsZero() {
+			elements = append(elements, num+".")
+			elements = append(elements, mt.White.elements()...)
+		}
+		if !mt.Black.IsZero() {
+			if mt.White.IsZero() || mt.White.comment() != "" || len(mt.White.Variations) > 0 {
+				elements = append(elements, num+"...")
+			}
+			elements = append(elements, mt.Black.elements()...)
+		}
+	}
+	return elements
+}
+
+// elements returns the move in SAN followed by its NAGs, its comment and its
+// variations
+func (m Move) elements() []string {
+	elements := []string{m.String()}
+	for _, nag := range m.NAGs {
+		elements = append(elements, "$"+strconv.Itoa(nag))
+	}
+	if c := m.comment(); c != "" {
+		elements = append(elements, "{"+c+"}")
+	}
+	for _, v := range m.Variations {
+		variation := movesElements(v.Comment, v.Movetext)
+		if len(variation) == 0 {
+			elements = append(elements, "()")
+			continue
+		}
+		variation[0] = "(" + variation[0]
+		variation[len(variation)-1] = variation[len(variation)-1] + ")"
+		elements = append(elements, variation...)
+	}
+	return elements
+}
+
+// String writes the move in SAN
+func (m Move) String() string {
+	s := string(m.Castle)
+	if m.Castle == "" {
+		if m.Piece != PiecePawn {
+			s = string(m.Piece)
+		}
+		s = s + string(m.FromFile)
+		if m.FromRank != 0 {
+			s = s + strconv.Itoa(int(m.FromRank))
+		}
+		if m.Capture {
+			s = s + "x"
+		}
+		s = s + string(m.File) + strconv.Itoa(int(m.Rank))
+		if m.Promotion != "" {
+			s = s + "=" + string(m.Promotion)
+		}
+	}
+	if m.Checkmate {
+		s = s + "#"
+	} else if m.Check {
+		s = s + "+"
+	}
+	return s
+}
+
+// quoteString writes a PGN string token, escaping quotes and backslashes
+func quoteString(s string) string {
+	s = strings.ReplaceAll(s, "\\", "\\\\")
+	s = strings.ReplaceAll(s, "\"", "\\\"")
+	return "\"" + s + "\""
+}
@@ -0,0 +1,115 @@
+package pgn_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestMarshal(t *testing.T) {
+	data := []struct {
+		name string
+		in   string
+		out  string
+	}{
+		{
+			name: "Tags and moves",
+			in: "[Event \"F/S Return Match\"]\n" +
+				"[White \"Fischer, \\\"Bobby\\\"\"]\n" +
+				"[Result \"1/2-1/2\"]\n" +
+				"\n" +
+				"1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 {This opening is called the Ruy Lopez.}\n" +
+				"4. Ba4 Nf6 5. O-O Be7 6. Re1 b5 12. cxb5 axb5 24. Bxf7+ Rxf7 43. Re6 1/2-1/2",
+			out: "[Event \"F/S Return Match\"]\n" +
+				"[White \"Fischer, \\\"Bobby\\\"\"]\n" +
+				"[Result \"1/2-1/2\"]\n" +
+				"\n" +
+				"1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 {This opening is called the Ruy Lopez.} " +
+				"4. Ba4 Nf6 5. O-O Be7 6. Re1 b5 12. cxb5 axb5 24. Bxf7+ Rxf7 43. Re6 1/2-1/2\n",
+		},
+		{
+			name: "Comment commands",
+			in:   "1. e4 { [%clk 0:03:00] [%eval 0.17] } e5 {Solid. [%emt 0:00:02] [%foo bar]}",
+			out:  "1. e4 {[%eval 0.17] [%clk 0:03:00]} 1... e5 {[%emt 0:00:02] [%foo bar] Solid.} *\n",
+		},
+	}
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			var unmarshalled pgn.PGN
+			err := pgn.Unmarshal(test.in, &unmarshalled)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := pgn.Marshal(unmarshalled)
+			if got != test.out {
+				fmt.Println("Got:")
+				fmt.Println(got)
+				fmt.Println("Exp:")
+				fmt.Println(test.out)
+				t.Fatal("Unexpected PGN")
+			}
+		})
+	}
+}
+
+func TestMarshalExport(t *testing.T) {
+	data := []struct {
+		name string
+		in   string
+		out  string
+	}{
+		{
+			name: "Tag order",
+			in: "[Result \"*\"]\n[Opening \"Ruy Lopez\"]\n[White \"Fischer\"]\n[ECO \"C95\"]\n" +
+				"[Event \"Match\"]\n\n1.e4   e5 *",
+			out: "[Event \"Match\"]\n[White \"Fischer\"]\n[Result \"*\"]\n[ECO \"C95\"]\n" +
+				"[Opening \"Ruy Lopez\"]\n\n1. e4 e5 *\n",
+		},
+		{
+			name: "Wrapping",
+			in: "1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 {This opening is called the Ruy Lopez.} " +
+				"4. Ba4 Nf6 5. O-O Be7 6. Re1 b5 7. Bb3 d6 8. c3 O-O 9. h3 Nb8 10. d4 Nbd7 1/2-1/2",
+			out: "1. e4 e5 2. Nf3 Nc6 3. Bb5 a6 {This opening is called the Ruy Lopez.} 4. Ba4\n" +
+				"Nf6 5. O-O Be7 6. Re1 b5 7. Bb3 d6 8. c3 O-O 9. h3 Nb8 10. d4 Nbd7 1/2-1/2\n",
+		},
+		{
+			name: "Move numbers kept with their moves",
+			in: "12. cxb5 axb5 13. Nc3 Bb7 14. Bg5 b4 15. Nb1 h6 16. Bh4 c5 17. dxe5 Nxe4 " +
+				"18. Bxe7 Qxe7 19. exd6 Qf6 *",
+			out: "12. cxb5 axb5 13. Nc3 Bb7 14. Bg5 b4 15. Nb1 h6 16. Bh4 c5 17. dxe5 Nxe4\n" +
+				"18. Bxe7 Qxe7 19. exd6 Qf6 *\n",
+		},
+		{
+			name: "NAGs and variations",
+			in:   "1. e4! e5 2. Nf3 (2. Nc3 {Vienna} Nf6 (2...Nc6 3.f4!?)) Nc6 $14 1-0",
+			out:  "1. e4 $1 e5 2. Nf3 (2. Nc3 {Vienna} 2... Nf6 (2... Nc6 3. f4 $5)) 2... Nc6 $14\n1-0\n",
+		},
+	}
+	for _, test := range data {
+		t.Run(test.name, func(t *testing.T) {
+			var unmarshalled pgn.PGN
+			err := pgn.Unmarshal(test.in, &unmarshalled)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := pgn.MarshalExport(unmarshalled)
+			if got != test.out {
+				fmt.Println("Got:")
+				fmt.Println(got)
+				fmt.Println("Exp:")
+				fmt.Println(test.out)
+				t.Fatal("Unexpected PGN")
+			}
+			unmarshalled = pgn.PGN{}
+			if err := pgn.Unmarshal(got, &unmarshalled); err != nil {
+				t.Fatal(err)
+			}
+			if again := pgn.MarshalExport(unmarshalled); again != got {
+				fmt.Println("Got:")
+				fmt.Println(again)
+				t.Fatal("Export is not stable")
+			}
+		})
+	}
+}
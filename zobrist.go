@@ -0,0 +1,146 @@
+package pgn
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+const ERR_ZOBRIST_TABLE = "Expected 781 hexadecimal 64-bit numbers"
+
+// Offsets into a ZobristTable, following the layout of the Polyglot opening
+// book format. The first 768 numbers are for pieces: 64 squares for each of
+// the twelve kinds of piece, black pawn, white pawn, black knight and so on up
+// to white king, with squares counted from a1 to h8, file first.
+const (
+	zobristCastling  = 768
+	zobristEnPassant = 772
+	zobristTurn      = 780
+	zobristSize      = 781
+)
+
+// ZobristTable holds the random numbers a Zobrist hash combines: one for each
+// piece on each square, one for each castling right, one for each file of an
+// en passant square and one for White to move.
+type ZobristTable [zobristSize]uint64
+
+// DefaultZobristTable is the table positions are hashed with when they are
+// created by NewPosition or ParseFEN. Its numbers come from a fixed seed, so
+// hashes are the same from run to run.
+//
+// Hashes follow the Polyglot key scheme, so a position has the key a Polyglot
+// opening book gives it when the table holds Polyglot's own Random64 numbers.
+// That table is not part of this package: load it with ParseZobristTable from
+// the Polyglot book format specification and set it here, before positions
+// are created, or on a position with SetZobristTable.
+var DefaultZobristTable = newZobristTable(0x9e3779b97f4a7c15)
+
+// newZobristTable fills a table with the splitmix64 sequence from seed
+func newZobristTable(seed uint64) *ZobristTable {
+	t := &ZobristTable{}
+	for i := range t {
+		seed += 0x9e3779b97f4a7c15
+		z := seed
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		t[i] = z ^ (z >> 31)
+	}
+	return t
+}
+
+// ParseZobristTable parses a table of 781 hexadecimal numbers, with or
+// without a 0x prefix, separated by commas or whitespace, as the Random64
+// array is printed in the Polyglot book format specification
+func ParseZobristTable(s string) (*ZobristTable, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || isWhiteSpace(r)
+	})
+	if len(fields) != zobristSize {
+		return nil, errors.New(ERR_ZOBRIST_TABLE)
+	}
+	t := &ZobristTable{}
+	for i, field := range fields {
+		field = strings.TrimPrefix(strings.TrimPrefix(field, "0x"), "0X")
+		field = strings.TrimSuffix(strings.TrimSuffix(field, "ULL"), "ull")
+		n, err := strconv.ParseUint(field, 16, 64)
+		if err != nil {
+			return nil, errors.New(ERR_ZOBRIST_TABLE)
+		}
+		t[i] = n
+	}
+	return t, nil
+}
+
+// Hash computes the Zobrist hash of the position from scratch
+func (t *ZobristTable) Hash(p *Position) uint64 {
+	var h uint64
+	for sq, c := range p.board {
+		if c != 0 {
+			h ^= t.piece(c, sq)
+		}
+	}
+	return h ^ t.state(p)
+}
+
+// piece returns the number for a piece, given by its FEN letter, on a square
+func (t *ZobristTable) piece(c byte, sq int) uint64 {
+	kind := 2 * strings.IndexByte("PNBRQK", upper(c))
+	if pieceColor(c) == White {
+		kind++
+	}
+	return t[64*kind+sq]
+}
+
+// state returns the numbers for the castling rights, en passant square and
+// side to move. As in Polyglot, the en passant square only counts when a pawn
+// of the side to move could capture onto it.
+func (t *ZobristTable) state(p *Position) uint64 {
+	var h uint64
+	for i, right := range []int{castleWhiteKingside, castleWhiteQueenside, castleBlackKingside, castleBlackQueenside} {
+		if p.castling&right != 0 {
+			h ^= t[zobristCastling+i]
+		}
+	}
+	if ep := p.capturableEnPassant(); ep >= 0 {
+		h ^= t[zobristEnPassant+fileOf(ep)]
+	}
+	if p.Turn == White {
+		h ^= t[zobristTurn]
+	}
+	return h
+}
+
+// Hash returns the Zobrist hash of the position. It is kept up to date as
+// moves are played rather than computed each time.
+func (p *Position) Hash() uint64 {
+	return p.hash
+}
+
+// SetZobristTable changes the table the position is hashed with, and the
+// table of the positions that follow from it by playing moves
+func (p *Position) SetZobristTable(t *ZobristTable) {
+	p.zobrist = t
+	p.hash = t.Hash(p)
+}
+
+// put places a piece, or nothing when c is zero, on a square, updating the
+// hash for the piece that leaves and the one that arrives
+func (p *Position) put(sq int, c byte) {
+	if p.zobrist != nil {
+		if old := p.board[sq]; old != 0 {
+			p.hash ^= p.zobrist.piece(old, sq)
+		}
+		if c != 0 {
+			p.hash ^= p.zobrist.piece(c, sq)
+		}
+	}
+	p.board[sq] = c
+}
+
+// toggleState adds or removes the castling, en passant and side to move
+// numbers from the hash, before and after they change
+func (p *Position) toggleState() {
+	if p.zobrist != nil {
+		p.hash ^= p.zobrist.state(p)
+	}
+}
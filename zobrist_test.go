@@ -0,0 +1,178 @@
+package pgn_test
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/miketmoore/pgn"
+)
+
+func TestHashIncremental(t *testing.T) {
+	fens := []string{
+		pgn.StartingFEN,
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"n1n5/PPPk4/8/8/8/8/4Kppp/5N1N b - - 0 1",
+		"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3",
+	}
+	for _, fen := range fens {
+		p, err := pgn.ParseFEN(fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, m := range p.LegalMoves() {
+			q := p
+			if err := q.Play(m); err != nil {
+				t.Fatal(err)
+			}
+			for _, m2 := range q.LegalMoves() {
+				r := q
+				if err := r.Play(m2); err != nil {
+					t.Fatal(err)
+				}
+				if r.Hash() != pgn.DefaultZobristTable.Hash(&r) {
+					fmt.Println("Position:", r.FEN())
+					t.Fatalf("Incremental hash differs after %s %s", m, m2)
+				}
+			}
+		}
+	}
+}
+
+func TestHashReplay(t *testing.T) {
+	b, err := os.ReadFile("data/games/fischer_spassky_1992_11_04.pgn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var unmarshalled pgn.PGN
+	if err := pgn.Unmarshal(string(b), &unmarshalled); err != nil {
+		t.Fatal(err)
+	}
+	err = unmarshalled.Games[0].Replay(func(ply pgn.Ply) error {
+		p, err := pgn.ParseFEN(ply.Position.FEN())
+		if err != nil {
+			return err
+		}
+		if p.Hash() != ply.Position.Hash() {
+			return fmt.Errorf("hash differs from the hash of the FEN after ply %d", ply.Index)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHashTranspositions(t *testing.T) {
+	hash := func(moves string) uint64 {
+		var unmarshalled pgn.PGN
+		if err := pgn.Unmarshal(moves, &unmarshalled); err != nil {
+			t.Fatal(err)
+		}
+		var last pgn.Position
+		unmarshalled.Games[0].Replay(func(ply pgn.Ply) error {
+			last = ply.Position
+			return nil
+		})
+		return last.Hash()
+	}
+	if hash("1. e4 e5 2. Nf3 Nc6 *") != hash("1. Nf3 Nc6 2. e4 e5 *") {
+		t.Fatal("Expected transpositions to hash the same, the en passant square on e6 not counting")
+	}
+	if hash("1. e4 d5 2. e5 f5 *") == hash("1. e4 f5 2. e5 d5 *") {
+		t.Fatal("Expected the en passant capture on f6 to change the hash")
+	}
+	start := pgn.NewPosition()
+	if hash("1. Nf3 Nf6 2. Ng1 Ng8 *") != start.Hash() {
+		t.Fatal("Expected the starting position")
+	}
+	if hash("1. Nf3 Nf6 2. Rg1 Ng8 3. Rh1 *") == hash("1. Nf3 Nf6 2. Ng1 Ng8 3. Nf3 *") {
+		t.Fatal("Expected lost castling rights to change the hash")
+	}
+}
+
+func TestParseZobristTable(t *testing.T) {
+	numbers := make([]string, 781)
+	for i := range numbers {
+		numbers[i] = fmt.Sprintf("0x%016X", i)
+	}
+	table, err := pgn.ParseZobristTable(strings.Join(numbers, ",\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// black king e8 is number 64*10+60, white king e1 is 64*11+4, castling
+	// rights start at 768, en passant files at 772 and White to move is 780
+	data := []struct {
+		fen  string
+		hash uint64
+	}{
+		{"4k3/8/8/8/8/8/8/4K3 b - - 0 1", 700 ^ 708},
+		{"4k3/8/8/8/8/8/8/4K3 w - - 0 1", 700 ^ 708 ^ 780},
+		{"4k2r/8/8/8/8/8/8/4K3 b k - 0 1", 700 ^ 708 ^ (64*6 + 63) ^ 770},
+		{"4k3/8/8/8/3Pp3/8/8/4K3 b - d3 0 1", 700 ^ 708 ^ (64*1 + 27) ^ (64*0 + 28) ^ 775},
+		{"4k3/8/8/8/3P4/8/8/4K3 b - d3 0 1", 700 ^ 708 ^ (64*1 + 27)},
+	}
+	for _, test := range data {
+		p, err := pgn.ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.SetZobristTable(table)
+		if p.Hash() != test.hash {
+			fmt.Printf("Got: %d\n", p.Hash())
+			fmt.Printf("Exp: %d\n", test.hash)
+			t.Fatalf("Unexpected hash for %s", test.fen)
+		}
+	}
+
+	if _, err := pgn.ParseZobristTable("0x1, 0x2"); err == nil || err.Error() != pgn.ERR_ZOBRIST_TABLE {
+		fmt.Println("Got:", err)
+		t.Fatal("Expected a table error")
+	}
+}
+
+// TestPolyglotKeys checks hashes against the test positions of the Polyglot
+// book format specification. It needs the specification's Random64 array,
+// which is not shipped with the package, in data/polyglot/random64.txt.
+func TestPolyglotKeys(t *testing.T) {
+	b, err := os.ReadFile("data/polyglot/random64.txt")
+	if os.IsNotExist(err) {
+		t.Skip("no Random64 array in data/polyglot/random64.txt")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	table, err := pgn.ParseZobristTable(string(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := []struct {
+		fen string
+		key uint64
+	}{
+		{pgn.StartingFEN, 0x463b96181691fc9c},
+		{"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", 0x823c9b50fd114196},
+		{"rnbqkbnr/ppp1pppp/8/3p4/4P3/8/PPPP1PPP/RNBQKBNR w KQkq d6 0 2", 0x0756b94461c50fb0},
+		{"rnbqkbnr/ppp1pppp/8/3pP3/8/8/PPPP1PPP/RNBQKBNR b KQkq - 0 2", 0x662fafb965db29d4},
+		{"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPP1PPP/RNBQKBNR w KQkq f6 0 3", 0x22a48b5a8e47ff78},
+		{"rnbqkbnr/ppp1p1pp/8/3pPp2/8/8/PPPPKPPP/RNBQ1BNR b kq - 0 3", 0x652a607ca3f242c1},
+		{"rnbq1bnr/ppp1pkpp/8/3pPp2/8/8/PPPPKPPP/RNBQ1BNR w - - 0 4", 0x00fdd303c946bdd9},
+		{"rnbqkbnr/p1pppppp/8/8/PpP4P/8/1P1PPPP1/RNBQKBNR b KQkq c3 0 3", 0x3c8123ea7b067637},
+		{"rnbqkbnr/p1pppppp/8/8/P6P/R1p5/1P1PPPP1/1NBQKBNR b Kkq - 0 4", 0x5c3f9b829b279560},
+	}
+	for _, test := range data {
+		p, err := pgn.ParseFEN(test.fen)
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.SetZobristTable(table)
+		if p.Hash() != test.key {
+			fmt.Printf("Got: %016x\n", p.Hash())
+			fmt.Printf("Exp: %016x\n", test.key)
+			t.Fatalf("Unexpected key for %s", test.fen)
+		}
+	}
+}